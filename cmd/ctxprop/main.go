@@ -0,0 +1,11 @@
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/cloverrose/rpcguard/passes/ctxprop"
+)
+
+func main() {
+	unitchecker.Main(ctxprop.Analyzer)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/cloverrose/rpcguard/passes/errdetails"
+)
+
+func main() {
+	unitchecker.Main(errdetails.Analyzer)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/cloverrose/rpcguard/passes/panics"
+)
+
+func main() {
+	unitchecker.Main(panics.Analyzer)
+}
@@ -0,0 +1,254 @@
+// Command rpcguard runs all rpcguard analyzers and writes a report.
+//
+//	rpcguard [-format=text|json|sarif] [-o file] [-inventory file] [-inventory.format=json|markdown] [-rpc_wraperr.IncludePackages=...] [packages]
+//
+// With -inventory, it also writes the list of RPC methods with verdicts of rpc_wraperr and rpc_callvalidate,
+// including skipped RPC methods and the reasons.
+//
+// With -stats, it writes timing of analyzers and per-phase timing and counters of rpc_wraperr as JSON.
+// -cpuprofile and -memprofile write pprof profiles, e.g. to find hot spots on a large repository.
+//
+// Unlike rpc_* commands for go vet, it analyzes all packages in one process,
+// so that the report (e.g. SARIF for GitHub code scanning) covers all packages.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/cloverrose/rpcguard/pkg/inventory"
+	"github.com/cloverrose/rpcguard/pkg/report"
+	"github.com/cloverrose/rpcguard/pkg/stats"
+
+	"github.com/cloverrose/rpcguard/passes/callvalidate"
+	"github.com/cloverrose/rpcguard/passes/ctxprop"
+	"github.com/cloverrose/rpcguard/passes/errdetails"
+	"github.com/cloverrose/rpcguard/passes/headers"
+	"github.com/cloverrose/rpcguard/passes/nilresponse"
+	"github.com/cloverrose/rpcguard/passes/panics"
+	"github.com/cloverrose/rpcguard/passes/wraperr"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+
+	formatMarkdown = "markdown"
+
+	// exitDiagnostics is exit code when diagnostics are reported, the same with singlechecker.
+	exitDiagnostics = 3
+)
+
+var analyzers = []*analysis.Analyzer{
+	callvalidate.Analyzer,
+	ctxprop.Analyzer,
+	errdetails.Analyzer,
+	headers.Analyzer,
+	nilresponse.Analyzer,
+	panics.Analyzer,
+	wraperr.Analyzer,
+}
+
+type options struct {
+	format          string
+	output          string
+	inventoryOutput string
+	inventoryFormat string
+	statsOutput     string
+	cpuProfile      string
+	memProfile      string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.format, "format", formatText, "output format (text, json, sarif)")
+	flag.StringVar(&opts.output, "o", "", "output file (default stdout)")
+	flag.StringVar(&opts.inventoryOutput, "inventory", "", "write inventory of RPC methods to the file")
+	flag.StringVar(&opts.inventoryFormat, "inventory.format", formatJSON, "inventory format (json, markdown)")
+	flag.StringVar(&opts.statsOutput, "stats", "", "write timing and statistics as JSON to the file")
+	flag.StringVar(&opts.cpuProfile, "cpuprofile", "", "write CPU profile to the file")
+	flag.StringVar(&opts.memProfile, "memprofile", "", "write memory profile to the file")
+	// analyzer flags are prefixed by analyzer name, the same with go vet. e.g. -rpc_wraperr.IncludePackages
+	for _, a := range analyzers {
+		a.Flags.VisitAll(func(f *flag.Flag) {
+			flag.Var(f.Value, a.Name+"."+f.Name, f.Usage)
+		})
+	}
+	flag.Parse()
+
+	code, err := run(opts, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rpcguard:", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func run(opts options, patterns []string) (int, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	if opts.cpuProfile != "" {
+		stop, err := startCPUProfile(opts.cpuProfile)
+		if err != nil {
+			return 0, err
+		}
+		defer stop()
+	}
+	if opts.statsOutput != "" && wraperr.StatsDir == "" {
+		// collect stats of packages in temporary directory unless -rpc_wraperr.StatsDir is given.
+		dir, err := os.MkdirTemp("", "rpcguard-stats-")
+		if err != nil {
+			return 0, err
+		}
+		defer os.RemoveAll(dir)
+		wraperr.StatsDir = dir
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax}, patterns...)
+	if err != nil {
+		return 0, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return 0, fmt.Errorf("%d errors during loading packages", n)
+	}
+	graph, err := checker.Analyze(analyzers, pkgs, nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return 0, fmt.Errorf("%s: %w", act, act.Err)
+		}
+	}
+	if opts.memProfile != "" {
+		if err := writeMemProfile(opts.memProfile); err != nil {
+			return 0, err
+		}
+	}
+	if opts.statsOutput != "" {
+		if err := writeStats(opts.statsOutput, graph); err != nil {
+			return 0, err
+		}
+	}
+
+	baseDir, err := os.Getwd()
+	if err != nil {
+		return 0, err
+	}
+	r := report.Collect(analyzers, graph.Roots, baseDir)
+	if opts.inventoryOutput != "" {
+		if err := writeInventory(opts.inventoryOutput, opts.inventoryFormat, inventory.Collect(graph.Roots, baseDir)); err != nil {
+			return 0, err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := write(w, opts.format, r); err != nil {
+		return 0, err
+	}
+	if len(r.Diagnostics) != 0 {
+		return exitDiagnostics, nil
+	}
+	return 0, nil
+}
+
+func write(w io.Writer, format string, r *report.Report) error {
+	switch format {
+	case formatText:
+		return report.WriteText(w, r)
+	case formatJSON:
+		return report.WriteJSON(w, r)
+	case formatSARIF:
+		return report.WriteSARIF(w, r)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func writeInventory(output, format string, inv *inventory.Inventory) (err error) {
+	var write func(io.Writer, *inventory.Inventory) error
+	switch format {
+	case formatJSON:
+		write = inventory.WriteJSON
+	case formatMarkdown:
+		write = inventory.WriteMarkdown
+	default:
+		return fmt.Errorf("unknown inventory format %q", format)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return write(file, inv)
+}
+
+// writeStats writes total execution time of each analyzer (including dependencies) and stats of packages.
+func writeStats(output string, graph *checker.Graph) error {
+	packages, err := stats.ReadDir(wraperr.StatsDir)
+	if err != nil {
+		return err
+	}
+	durations := make(map[string]time.Duration)
+	for act := range graph.All() {
+		durations[act.Analyzer.Name] += act.Duration
+	}
+	data, err := json.MarshalIndent(stats.Summarize(packages, durations), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, append(data, '\n'), 0o600)
+}
+
+func startCPUProfile(output string) (stop func(), err error) {
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		file.Close()
+	}, nil
+}
+
+func writeMemProfile(output string) (err error) {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	// get up-to-date statistics, the same with go test -memprofile.
+	runtime.GC()
+	return pprof.Lookup("allocs").WriteTo(file, 0)
+}
@@ -2,10 +2,20 @@ package rpcguard
 
 import (
 	"github.com/cloverrose/rpcguard/passes/callvalidate"
+	"github.com/cloverrose/rpcguard/passes/ctxprop"
+	"github.com/cloverrose/rpcguard/passes/errdetails"
+	"github.com/cloverrose/rpcguard/passes/headers"
+	"github.com/cloverrose/rpcguard/passes/nilresponse"
+	"github.com/cloverrose/rpcguard/passes/panics"
 	"github.com/cloverrose/rpcguard/passes/wraperr"
 )
 
 func init() {
 	callvalidate.RegisterPlugin()
+	ctxprop.RegisterPlugin()
+	errdetails.RegisterPlugin()
+	headers.RegisterPlugin()
+	nilresponse.RegisterPlugin()
+	panics.RegisterPlugin()
 	wraperr.RegisterPlugin()
 }
@@ -4,12 +4,17 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/buildssa"
 	"golang.org/x/tools/go/ssa"
 
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
 	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/inventory"
 	"github.com/cloverrose/rpcguard/pkg/logger"
 	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
 )
@@ -29,66 +34,72 @@ var Analyzer = &analysis.Analyzer{
 	Requires: []*analysis.Analyzer{
 		buildssa.Analyzer,
 	},
-	Flags: *flag.NewFlagSet("rpc_callvalidate", flag.ExitOnError),
+	Flags:      *flag.NewFlagSet("rpc_callvalidate", flag.ExitOnError),
+	ResultType: reflect.TypeOf((*inventory.Result)(nil)),
 }
 
 func init() {
 	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
 	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
 	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
 	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
 	Analyzer.Flags.StringVar(&ValidateMethods, "ValidateMethods", ValidateMethods, "Validate methods")
 }
 
 func setupAndRun(pass *analysis.Pass) (any, error) {
-	closer, err := logger.SetDefault(LogConfig, pass)
+	log, closer, err := logger.New(LogConfig, pass)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := closer(); err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(os.Stderr, err)
 		}
 	}()
 
 	// any files that are not excluded are target.
-	fileFilter, err = filter.New(`.*`, ExcludeFiles)
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
 	if err != nil {
 		return nil, err
 	}
 
-	return run(pass)
+	return run(pass, log, fileFilter)
 }
 
-func run(pass *analysis.Pass) (interface{}, error) {
-	currentPackage := pass.Pkg.Path()
-	slog.Debug("analyzing package", slog.String("package", currentPackage))
+// run analyzes the package and returns verdicts of RPC methods.
+func run(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter) (*inventory.Result, error) {
+	log.Debug("analyzing package")
 
 	// Phase 1: Get SSA
 	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
 	if !ok {
 		panic("failed to get SSA")
 	}
+	defer ssawalk.IndexFields(ssaData.Pkg.Prog)()
 
-	// Phase 2: Func is target?
-	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
-	for _, srcFunc := range ssaData.SrcFuncs {
-		if isTargetFunc(pass, srcFunc) {
-			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
-		}
-	}
-
-	// Phase 3: Func is RPC method?.
+	// Phase 2: Func is RPC method?.
+	result := &inventory.Result{}
 	rpcAnalyzer := rpcmethod.BuildChecker(pass)
 	if rpcAnalyzer == nil {
-		slog.Debug("skip package (no rpc method types)", slog.String("package", currentPackage))
-		return nil, nil
+		log.Debug("skip package (no rpc method types)")
+		result.SkipPackage(pass, "RPC method types are not resolved (connectrpc.com/connect is not imported)")
+		return result, nil
 	}
-	rpcMethods := make([]*ssa.Function, 0, len(targetSrcFuncs))
-	for _, fn := range targetSrcFuncs {
-		if rpcAnalyzer.IsRPCMethod(fn) {
-			rpcMethods = append(rpcMethods, fn)
+
+	// Phase 3: Func is target?
+	rpcMethods := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, fn := range ssaData.SrcFuncs {
+		if !rpcAnalyzer.IsRPCMethod(fn) {
+			continue
+		}
+		if reason := skipReason(pass, fileFilter, fn); reason != "" {
+			log.Debug("skip Function ("+reason+")", logger.Attr(fn))
+			result.Add(fn, inventory.VerdictSkipped, reason)
+			continue
 		}
+		rpcMethods = append(rpcMethods, fn)
 	}
 
 	// Phase 4: Check validate call
@@ -99,14 +110,23 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	for _, srcFunc := range rpcMethods {
 		ok, err := checkCallValidate(srcFunc, validateMethods)
 		if err != nil {
-			return nil, err
+			if !ssawalk.IsUnsupported(err) {
+				return nil, err
+			}
+			// can't analyze the method, report it instead of judging it ok or bad.
+			result.Add(srcFunc, inventory.VerdictSkipped, "cannot be analyzed: "+err.Error())
+			rpcmethod.ReportNotAnalyzed(pass, srcFunc, err)
+			continue
 		}
 		if !ok {
+			result.Add(srcFunc, inventory.VerdictBad, "")
 			report(pass, srcFunc, validateMethods, ValidateMethods)
+			continue
 		}
+		result.Add(srcFunc, inventory.VerdictOK, "")
 	}
 
-	return nil, nil
+	return result, nil
 }
 
 func report(pass *analysis.Pass, srcFunc *ssa.Function, validateMethods []Method, validateMethodsStr string) {
@@ -125,14 +145,17 @@ func report(pass *analysis.Pass, srcFunc *ssa.Function, validateMethods []Method
 	pass.Reportf(srcFunc.Pos(), customReportMsgTemplateMoreMethods, srcFunc.Name(), validateMethodsStr)
 }
 
-func isTargetFunc(pass *analysis.Pass, srcFunc *ssa.Function) bool {
+// skipReason returns why srcFunc is not target. It returns empty string if srcFunc is target.
+func skipReason(pass *analysis.Pass, fileFilter *filter.Filter, srcFunc *ssa.Function) string {
 	if srcFunc == nil {
 		panic("srcFunc is nil")
 	}
 	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
-	if !fileFilter.IsTarget(fileName) {
-		slog.Debug("skip Function (non target file)", logger.Attr(srcFunc))
-		return false
+	if e := fileFilter.Explain(fileName); !e.Target {
+		return "file is excluded by " + strconv.Quote(e.Exclude)
+	}
+	if SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos()) {
+		return "generated file"
 	}
-	return true
+	return ""
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gostaticanalysis/testutil"
 
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
 	"github.com/cloverrose/rpcguard/passes/callvalidate"
 )
 
@@ -15,8 +17,8 @@ func Test(t *testing.T) {
 	t.Parallel()
 	testdata := analysistest.TestData()
 	testdata = testutil.WithModules(t, testdata, nil)
-	callvalidate.LogConfig.Level = "INFO"
-	callvalidate.ValidateMethods = "buf.build/go/protovalidate:Validate,a:customValidate"
+	configtest.Set(t, &callvalidate.LogConfig.Level, "INFO")
+	configtest.Set(t, &callvalidate.ValidateMethods, "buf.build/go/protovalidate:Validate,a:customValidate")
 	pkgs := "a"
 	analysistest.Run(t, testdata, callvalidate.Analyzer, strings.Split(pkgs, ",")...)
 }
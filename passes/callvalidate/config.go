@@ -4,14 +4,14 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/cloverrose/rpcguard/pkg/filter"
 	"github.com/cloverrose/rpcguard/pkg/logger"
 )
 
-// log related configuration.
+// log related configuration. See logger.Config.
 var LogConfig = logger.Config{
-	Level:  "INFO",
+	Level:  "WARN",
 	File:   "",
+	Dir:    "",
 	Format: "json",
 }
 
@@ -21,14 +21,17 @@ var LogConfig = logger.Config{
 // -rpc_callvalidate.ExcludeFiles='.+_test\.go,.+\.connect\.go'
 var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
 
+// SkipGenerated is configuration whether skip functions in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Default is true.
+var SkipGenerated = true
+
 // ValidateMethods is configuration which methods should be called.
 // Default is "buf.build/go/protovalidate:Validate,github.com/bufbuild/protovalidate-go:Validate"
 // Package and Method join with `:`
 // You can specify multiple methods by using `,` separated value.
 var ValidateMethods = "buf.build/go/protovalidate:Validate,github.com/bufbuild/protovalidate-go:Validate"
 
-var fileFilter *filter.Filter
-
 type Method struct {
 	packagePath string
 	name        string
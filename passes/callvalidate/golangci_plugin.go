@@ -24,6 +24,7 @@ func newPlugin(conf any) (register.LinterPlugin, error) {
 type settings struct {
 	Log             logger.Config
 	ExcludeFiles    string
+	SkipGenerated   *bool
 	ValidateMethods string
 }
 
@@ -38,12 +39,18 @@ func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
 	if p.settings.Log.File != "" {
 		LogConfig.File = p.settings.Log.File
 	}
+	if p.settings.Log.Dir != "" {
+		LogConfig.Dir = p.settings.Log.Dir
+	}
 	if p.settings.Log.Format != "" {
 		LogConfig.Format = p.settings.Log.Format
 	}
 	if p.settings.ExcludeFiles != "" {
 		ExcludeFiles = p.settings.ExcludeFiles
 	}
+	if p.settings.SkipGenerated != nil {
+		SkipGenerated = *p.settings.SkipGenerated
+	}
 	if p.settings.ValidateMethods != "" {
 		ValidateMethods = p.settings.ValidateMethods
 	}
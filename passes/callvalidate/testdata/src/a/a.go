@@ -141,3 +141,18 @@ func (app *App) CallCustomValidateAndReturnError(ctx context.Context, req *conne
 func customValidate(msg *Message) error {
 	return errors.New("err")
 }
+
+func (app *App) CallValidateChosenAtRuntime(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // want `rpcguard could not analyze CallValidateChosenAtRuntime: multiple functions are source of the value`
+	validate := customValidate
+	if req.Msg.text == "" {
+		validate = otherValidate
+	}
+	if err := validate(req.Msg); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return connect.NewResponse(&Message{"hello"}), nil
+}
+
+func otherValidate(msg *Message) error {
+	return nil
+}
@@ -1,8 +1,6 @@
 package callvalidate
 
 import (
-	"errors"
-
 	"golang.org/x/tools/go/ssa"
 
 	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
@@ -15,7 +13,8 @@ type visitorPlugin struct {
 
 func (p *visitorPlugin) VisitFunction(val *ssa.Function) error {
 	if p.fn != nil {
-		return errors.New("fn is not nil")
+		// E.g. validate := v1.Validate; if cond { validate = v2.Validate }
+		return &ssawalk.UnsupportedError{Value: val, Reason: "multiple functions are source of the value"}
 	}
 	p.fn = val
 	return nil
@@ -0,0 +1,140 @@
+package ctxprop
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+)
+
+const contextPath = "context"
+
+// getCalls returns all calls in fn.
+// go and defer statements are not included because goroutine can outlive RPC method,
+// and it is common to detach context intentionally.
+func getCalls(fn *ssa.Function) []*ssa.Call {
+	var calls []*ssa.Call
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if call, ok := instr.(*ssa.Call); ok {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// getCallees returns static callees of calls in fn.
+func getCallees(fn *ssa.Function) []*ssa.Function {
+	var callees []*ssa.Function
+	for _, call := range getCalls(fn) {
+		if callee := call.Call.StaticCallee(); callee != nil {
+			callees = append(callees, callee)
+		}
+	}
+	return callees
+}
+
+// hasDetachedContextArg returns true if call receives context that is not derived from caller's ctx parameter.
+func hasDetachedContextArg(call *ssa.Call, factWrapper *factutil.FactWrapper[*detachesContext]) (bool, error) {
+	if isContextPackage(call.Call.StaticCallee()) {
+		// context.WithXXX(context.Background()) just creates context.
+		// Report the call that receives created context instead.
+		return false, nil
+	}
+	for _, arg := range call.Call.Args {
+		if !isContextType(arg.Type()) {
+			continue
+		}
+		detached, err := isDetached(call.Parent(), arg, factWrapper)
+		if err != nil {
+			return false, err
+		}
+		if detached {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isDetached scans value and returns true if value doesn't derive from ctx parameter of fn (or its enclosing functions).
+func isDetached(fn *ssa.Function, val ssa.Value, factWrapper *factutil.FactWrapper[*detachesContext]) (bool, error) {
+	plugin := newVisitorPlugin(fn, factWrapper)
+	if err := plugin.walk(val); err != nil {
+		return false, err
+	}
+	return plugin.detached, nil
+}
+
+// receiver returns the receiver of method call, or nil if call is not a method call.
+func receiver(call *ssa.CallCommon) ssa.Value {
+	if call.IsInvoke() {
+		return call.Value
+	}
+	callee := call.StaticCallee()
+	if callee == nil || callee.Signature.Recv() == nil || len(call.Args) == 0 {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// returnedContexts returns contexts returned by fn.
+func returnedContexts(fn *ssa.Function) []ssa.Value {
+	var values []ssa.Value
+	for _, block := range fn.Blocks {
+		if len(block.Instrs) == 0 {
+			continue
+		}
+		rtn, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return)
+		if !ok {
+			continue
+		}
+		for _, result := range rtn.Results {
+			if isContextType(result.Type()) {
+				values = append(values, result)
+			}
+		}
+	}
+	return values
+}
+
+// calleeName returns readable name of the function called by call.
+func calleeName(call *ssa.CallCommon) string {
+	if call.IsInvoke() {
+		return call.Method.FullName()
+	}
+	if callee := call.StaticCallee(); callee != nil {
+		return callee.String()
+	}
+	return call.Value.Name()
+}
+
+// isContextType returns true if typ is context.Context.
+func isContextType(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return false
+	}
+	return obj.Pkg().Path() == contextPath && obj.Name() == "Context"
+}
+
+// isRootContext returns true if fn is context.Background or context.TODO.
+func isRootContext(fn *ssa.Function) bool {
+	if !isContextPackage(fn) {
+		return false
+	}
+	return fn.Name() == "Background" || fn.Name() == "TODO"
+}
+
+// isContextPackage returns true if fn is defined in context package.
+func isContextPackage(fn *ssa.Function) bool {
+	if fn == nil || fn.Pkg == nil || fn.Pkg.Pkg == nil {
+		return false
+	}
+	return fn.Pkg.Pkg.Path() == contextPath
+}
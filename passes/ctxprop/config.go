@@ -0,0 +1,49 @@
+package ctxprop
+
+import (
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+// log related configuration. See logger.Config.
+var LogConfig = logger.Config{
+	Level:  "WARN",
+	File:   "",
+	Dir:    "",
+	Format: "json",
+}
+
+// IncludePackages is configuration which packages should be included.
+// Multiple Packages can be specified by using commas (,) or a JSON array.
+// Each pattern is a regexp or a Go package pattern (./..., github.com/foo/bar/...), and "!" negates a pattern.
+// e.g. github.com/foo/bar/a/includedpkg/hello,github.com/foo/bar/common/...,!./internal/mock/...
+// If it is empty, all packages of the main module of the analyzed package are included
+// (and all modules used by go.work if the module is in a workspace), like "$(go list -m)/.*".
+//
+// ctxprop follows helpers called from RPC method only when the helper is defined in included packages.
+// If RPC method calls helper in excluded package, ctxprop can't know whether the helper uses context not derived from its ctx (e.g. context.Background()) or not.
+// Thus, ctxprop doesn't report such calls.
+var IncludePackages = ""
+
+// ExcludePackages is configuration which packages should be excluded.
+// This is useful to exclude vendor.
+var ExcludePackages = ""
+
+// ExcludeFiles is configuration which files should be excluded.
+// This is useful to exclude test file, generated files.
+// To set the same value with the default config, use this command line argument.
+// -rpc_ctxprop.ExcludeFiles='.+_test\.go,.+\.connect\.go'
+var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
+
+// SkipGenerated is configuration whether skip RPC methods in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Functions in generated files are still analyzed and their facts are exported, so that callers are judged correctly.
+// Default is true.
+var SkipGenerated = true
+
+// passTargets holds filters built from the configuration for each pass.
+// It is passed explicitly instead of package variables, since passes of packages run concurrently.
+type passTargets struct {
+	packages *filter.Filter
+	files    *filter.Filter
+}
@@ -0,0 +1,213 @@
+package ctxprop
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/graph"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+const (
+	doc          = "rpc_ctxprop checks if RPC method propagates its context to downstream calls."
+	reportMsg    = "RPC method %s calls %s with context that is not derived from its ctx"
+	reportMsgVia = "RPC method %s calls %s that uses context not derived from its ctx: %s"
+)
+
+// Analyzer checks if RPC method propagates its context properly.
+var Analyzer = &analysis.Analyzer{
+	Name: "rpc_ctxprop",
+	Doc:  doc,
+	Run:  setupAndRun,
+	Requires: []*analysis.Analyzer{
+		buildssa.Analyzer,
+	},
+	Flags: *flag.NewFlagSet("rpc_ctxprop", flag.ExitOnError),
+	FactTypes: []analysis.Fact{
+		&detachesContext{},
+	},
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
+	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
+	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
+	Analyzer.Flags.StringVar(&IncludePackages, "IncludePackages", IncludePackages, "include packages")
+	Analyzer.Flags.StringVar(&ExcludePackages, "ExcludePackages", ExcludePackages, "exclude packages")
+	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
+}
+
+func setupAndRun(pass *analysis.Pass) (any, error) {
+	log, closer, err := logger.New(LogConfig, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := closer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	packageFilter, _, skipReason, err := filter.ForPass(pass, log, IncludePackages, ExcludePackages)
+	if err != nil {
+		return nil, err
+	}
+	if skipReason != "" {
+		return nil, nil
+	}
+
+	// any files that are not excluded are target.
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(pass, log, &passTargets{packages: packageFilter, files: fileFilter})
+}
+
+//nolint:gocognit,cyclop // main routine
+func run(pass *analysis.Pass, log *slog.Logger, targets *passTargets) (interface{}, error) {
+	currentPackage := pass.Pkg.Path()
+	log.Debug("analyzing package")
+
+	// Phase 1: Package is target?
+	if !targets.packages.IsTarget(currentPackage) {
+		log.Debug("skip package (not target package)", slog.String("reason", targets.packages.Explain(currentPackage).String()))
+		return nil, nil
+	}
+
+	// Phase 2: Get SSA
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		panic("failed to get SSA")
+	}
+
+	// Phase 3: Func is target?
+	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, srcFunc := range ssaData.SrcFuncs {
+		if isTargetFunc(pass, log, targets, srcFunc) {
+			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
+		}
+	}
+
+	// closure func (ends with $1) Object() == nil, then we can't export facts.
+	// Thus, use this localFacts during package check.
+	factWrapper := factutil.NewFactWrapper[*detachesContext](pass)
+
+	// Phase 4: Export facts for funcs that pass detached context by themselves.
+	for _, srcFunc := range targetSrcFuncs {
+		var notAnalyzed bool
+		for _, call := range getCalls(srcFunc) {
+			detached, err := hasDetachedContextArg(call, factWrapper)
+			if err != nil {
+				if !ssawalk.IsUnsupported(err) {
+					return nil, err
+				}
+				// can't analyze the call, report srcFunc once instead of judging the call ok.
+				if !notAnalyzed && !(SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos())) {
+					rpcmethod.ReportNotAnalyzed(pass, srcFunc, err)
+				}
+				notAnalyzed = true
+				continue
+			}
+			if detached {
+				factWrapper.Export(srcFunc, &detachesContext{Path: []string{srcFunc.String(), calleeName(&call.Call)}})
+				break
+			}
+		}
+	}
+
+	// Phase 5: Create SCCs (this sccs are topologically sorted) and propagate facts to callers.
+	g := graph.NewGraph[*ssa.Function]()
+	for _, srcFunc := range targetSrcFuncs {
+		for _, callee := range getCallees(srcFunc) {
+			g.AddEdge(srcFunc, callee)
+		}
+	}
+	log.Debug("build graph", slog.Any("graph", g))
+	sccs := graph.Decomposition(g)
+	log.Debug("Strongly Connected Components", slog.Any("sccs", graph.SCCs[*ssa.Function](sccs)))
+	propagation.Run(pass, log, sccs, factWrapper)
+
+	// Phase 6: Check RPC method passes its ctx to downstream calls.
+	rpcChecker := rpcmethod.BuildChecker(pass)
+	if rpcChecker == nil {
+		log.Debug("skip package (no rpc method types)")
+		return nil, nil
+	}
+	for _, fn := range targetSrcFuncs {
+		if !rpcChecker.IsRPCMethod(fn) {
+			continue
+		}
+		if SkipGenerated && filter.IsGenerated(pass, fn.Pos()) {
+			log.Debug("skip RPC method (generated file)", logger.Attr(fn))
+			continue
+		}
+		log.Info("found RPC method", logger.Attr(fn))
+		if err := checkRPCMethod(pass, factWrapper, fn); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// propagation propagates facts from callees to callers.
+// All funcs in the same SCC reach each other, so if one of them detaches context, all of them detach context.
+var propagation = &factutil.Propagation[*detachesContext]{
+	Callees: getCallees,
+	Derive: func(fn *ssa.Function, source *detachesContext) *detachesContext {
+		return &detachesContext{Path: append([]string{fn.String()}, source.Path...)}
+	},
+}
+
+// checkRPCMethod reports calls in fn that receive context not derived from fn's ctx.
+func checkRPCMethod(pass *analysis.Pass, factWrapper *factutil.FactWrapper[*detachesContext], fn *ssa.Function) error {
+	for _, call := range getCalls(fn) {
+		detached, err := hasDetachedContextArg(call, factWrapper)
+		if err != nil {
+			if !ssawalk.IsUnsupported(err) {
+				return err
+			}
+			// can't analyze the call, fn is already reported in Phase 4.
+			continue
+		}
+		if detached {
+			pass.Reportf(call.Pos(), reportMsg, fn.Name(), calleeName(&call.Call))
+			continue
+		}
+		callee := call.Call.StaticCallee()
+		if callee == nil || callee == fn {
+			continue
+		}
+		if fact, ok := factWrapper.Import(callee); ok {
+			pass.Reportf(call.Pos(), reportMsgVia, fn.Name(), callee.String(), strings.Join(fact.Path, " -> "))
+		}
+	}
+	return nil
+}
+
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, targets *passTargets, srcFunc *ssa.Function) bool {
+	if srcFunc == nil {
+		panic("srcFunc is nil")
+	}
+	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
+	if !targets.files.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+		return false
+	}
+	return true
+}
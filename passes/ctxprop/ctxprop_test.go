@@ -0,0 +1,24 @@
+package ctxprop_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
+	"github.com/cloverrose/rpcguard/passes/ctxprop"
+)
+
+func Test(t *testing.T) {
+	t.Parallel()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &ctxprop.LogConfig.Level, "INFO")
+	pkgs := "a/a01core,a/a02helper,a/a02helper/helper"
+	configtest.Set(t, &ctxprop.IncludePackages, "^(a/a01core|a/a02helper|a/a02helper/helper)$")
+	analysistest.Run(t, testdata, ctxprop.Analyzer, strings.Split(pkgs, ",")...)
+}
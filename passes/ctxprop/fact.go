@@ -0,0 +1,18 @@
+package ctxprop
+
+import (
+	"strings"
+)
+
+// detachesContext is a fact for functions that call a function with a context
+// which is not derived from the caller's ctx, directly or through its callees.
+type detachesContext struct {
+	// Path is call chain from the function to the function that receives the detached context.
+	Path []string
+}
+
+func (f *detachesContext) AFact() {}
+
+func (f *detachesContext) String() string {
+	return "detachesContext(" + strings.Join(f.Path, " -> ") + ")"
+}
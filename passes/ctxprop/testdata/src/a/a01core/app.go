@@ -0,0 +1,59 @@
+package a01core
+
+import (
+	"context"
+)
+
+type repository interface {
+	Find(ctx context.Context, id string) (string, error)
+}
+
+type App struct {
+	repo   repository
+	ctx    context.Context
+	holder ctxHolder
+}
+
+type ctxHolder interface {
+	Context() context.Context
+}
+
+// session holds context of the request.
+type session struct {
+	ctx context.Context
+}
+
+func (s *session) Context() context.Context {
+	return s.ctx
+}
+
+// findWithSession uses context of session given by the caller.
+func findWithSession(s *session, id string) (string, error) {
+	return find(s.Context(), id)
+}
+
+// findWithHolder uses context of holder given by the caller.
+func findWithHolder(h ctxHolder, id string) (string, error) {
+	return find(h.Context(), id)
+}
+
+// NewApp stores ctx of the caller, not of each RPC method.
+func NewApp(ctx context.Context, repo repository) *App {
+	return &App{repo: repo, ctx: ctx}
+}
+
+var globalCtx = context.Background()
+
+func newContext() context.Context {
+	return context.Background()
+}
+
+type Message struct {
+	text string
+}
+
+type ctxKey struct{}
+
+func find(ctx context.Context, id string) (string, error) {
+	return id, nil
+}
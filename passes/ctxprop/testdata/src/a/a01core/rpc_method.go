@@ -0,0 +1,239 @@
+package a01core
+
+// This file contains RPC methods.
+// Note: The ctxprop specifically reports only on RPC methods.
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// PropagateCtx passes ctx to downstream call.
+func (app *App) PropagateCtx(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	text, err := app.repo.Find(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// PropagateDerivedCtx passes context derived from ctx to downstream call.
+func (app *App) PropagateDerivedCtx(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	ctx2, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	text, err := find(context.WithValue(ctx2, ctxKey{}, "value"), "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// PropagateCtxInLoop passes context derived from ctx in loop to downstream call.
+func (app *App) PropagateCtxInLoop(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	for i := range 3 {
+		ctx = context.WithValue(ctx, ctxKey{}, i)
+	}
+	text, err := find(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseBackground passes context.Background() to downstream call.
+func (app *App) UseBackground(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseBackground:"detachesContext\\(\\(\\*a/a01core.App\\).UseBackground -> a/a01core.find\\)"
+	text, err := find(context.Background(), "id") // want "RPC method UseBackground calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseTODO passes context.TODO() to interface method.
+func (app *App) UseTODO(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseTODO:"detachesContext.*"
+	text, err := app.repo.Find(context.TODO(), "id") // want "RPC method UseTODO calls \\(a/a01core.repository\\).Find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseDerivedBackground passes context derived from context.Background() to downstream call.
+func (app *App) UseDerivedBackground(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseDerivedBackground:"detachesContext.*"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	text, err := find(ctx, "id") // want "RPC method UseDerivedBackground calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// PassNil passes nil as context to downstream call.
+func (app *App) PassNil(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want PassNil:"detachesContext.*"
+	text, err := find(nil, "id") // want "RPC method PassNil calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseBackgroundInGoroutine passes context.Background() to goroutine.
+// goroutine can outlive RPC method, so it is not reported.
+func (app *App) UseBackgroundInGoroutine(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	go find(context.Background(), "id") //nolint:errcheck // goroutine
+	text, err := find(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// CallHelperUsingBackground calls helper that uses context.Background().
+func (app *App) CallHelperUsingBackground(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallHelperUsingBackground:"detachesContext.*"
+	text, err := app.helperL1(ctx) // want "RPC method CallHelperUsingBackground calls \\(\\*a/a01core.App\\).helperL1 that uses context not derived from its ctx: \\(\\*a/a01core.App\\).helperL1 -> \\(\\*a/a01core.App\\).helperL2 -> \\(a/a01core.repository\\).Find"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+func (app *App) helperL1(ctx context.Context) (string, error) { // want helperL1:"detachesContext.*"
+	return app.helperL2()
+}
+
+func (app *App) helperL2() (string, error) { // want helperL2:"detachesContext.*"
+	return app.repo.Find(context.Background(), "id")
+}
+
+// CallRecursiveHelper calls recursive helpers that use context.Background().
+func (app *App) CallRecursiveHelper(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallRecursiveHelper:"detachesContext.*"
+	text, err := app.ping(ctx, 3) // want "RPC method CallRecursiveHelper calls \\(\\*a/a01core.App\\).ping that uses context not derived from its ctx: .*"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+func (app *App) ping(ctx context.Context, n int) (string, error) { // want ping:"detachesContext.*"
+	if n == 0 {
+		return find(ctx, "id")
+	}
+	return app.pong(ctx, n-1)
+}
+
+func (app *App) pong(ctx context.Context, n int) (string, error) { // want pong:"detachesContext.*"
+	if n == 1 {
+		return find(context.Background(), "id")
+	}
+	return app.ping(ctx, n-1)
+}
+
+// CallHelperPropagatingCtx calls helper that propagates ctx.
+func (app *App) CallHelperPropagatingCtx(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	text, err := app.helperOK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+func (app *App) helperOK(ctx context.Context) (string, error) {
+	return app.repo.Find(ctx, "id")
+}
+
+// UseFieldCtx passes context stored in struct field to downstream call.
+func (app *App) UseFieldCtx(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseFieldCtx:"detachesContext.*"
+	text, err := find(app.ctx, "id") // want "RPC method UseFieldCtx calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseGlobalCtx passes global context to downstream call.
+func (app *App) UseGlobalCtx(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseGlobalCtx:"detachesContext.*"
+	text, err := find(globalCtx, "id") // want "RPC method UseGlobalCtx calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseHelperCtx passes context created by helper that takes no context.
+func (app *App) UseHelperCtx(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseHelperCtx:"detachesContext.*"
+	text, err := find(newContext(), "id") // want "RPC method UseHelperCtx calls a/a01core.find with context that is not derived from its ctx"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// PropagateCtxInClosure passes ctx captured by closure to downstream call.
+func (app *App) PropagateCtxInClosure(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	var text string
+	run := func() error {
+		var err error
+		text, err = find(ctx, "id")
+		return err
+	}
+	if err := run(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// PropagateReassignedCtxInClosure passes ctx captured by reference to downstream call.
+func (app *App) PropagateReassignedCtxInClosure(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	var text string
+	run := func() error {
+		var err error
+		text, err = find(ctx, "id")
+		return err
+	}
+	ctx = context.WithValue(ctx, ctxKey{}, "value")
+	if err := run(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseReassignedBackgroundInClosure passes context.Background() captured by reference to downstream call.
+func (app *App) UseReassignedBackgroundInClosure(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want UseReassignedBackgroundInClosure:"detachesContext.*"
+	var text string
+	run := func() error {
+		var err error
+		text, err = find(ctx, "id")
+		return err
+	}
+	ctx = context.Background()
+	if err := run(); err != nil { // want "RPC method UseReassignedBackgroundInClosure calls \\(\\*a/a01core.App\\).UseReassignedBackgroundInClosure\\$1 that uses context not derived from its ctx: .*"
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// UseHolderCtx passes context of holder stored in struct field, which can't be analyzed.
+func (app *App) UseHolderCtx(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want "rpcguard could not analyze UseHolderCtx: context returned by method of untrackable receiver: .*"
+	text, err := find(app.holder.Context(), "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// CallHelperUsingAccessor calls helpers that use context of the given session and holder.
+func (app *App) CallHelperUsingAccessor(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	text, err := findWithSession(&session{ctx: ctx}, "id")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findWithHolder(app.holder, "id"); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
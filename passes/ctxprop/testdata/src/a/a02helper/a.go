@@ -0,0 +1,33 @@
+package a02helper
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+
+	"a/a02helper/helper"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// CallHelperInOtherPackage calls helper defined in other included package.
+func (app *App) CallHelperInOtherPackage(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallHelperInOtherPackage:"detachesContext.*"
+	text, err := helper.FindDetached("id") // want "RPC method CallHelperInOtherPackage calls a/a02helper/helper.FindDetached that uses context not derived from its ctx: a/a02helper/helper.FindDetached -> a/a02helper/helper.Find"
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// CallHelperPropagatingCtx calls helper that propagates ctx.
+func (app *App) CallHelperPropagatingCtx(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	text, err := helper.Find(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{text}), nil
+}
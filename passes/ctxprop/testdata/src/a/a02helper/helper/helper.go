@@ -0,0 +1,13 @@
+package helper
+
+import (
+	"context"
+)
+
+func Find(ctx context.Context, id string) (string, error) {
+	return id, nil
+}
+
+func FindDetached(id string) (string, error) { // want FindDetached:"detachesContext\\(a/a02helper/helper.FindDetached -> a/a02helper/helper.Find\\)"
+	return Find(context.Background(), id)
+}
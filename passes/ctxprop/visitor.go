@@ -0,0 +1,153 @@
+package ctxprop
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+)
+
+// visitorPlugin visit ssa.Value and checks if the context derives from ctx parameter of the function.
+// The context is derived only if all of its sources reach context parameters of the function or its enclosing functions
+// (closures capture ctx of the enclosing function).
+// Sources known to be detached are context.Background(), context.TODO(), nil, globals, struct fields,
+// and calls to functions that detach context by themselves (E.g. helpers returning context.Background()).
+// Other sources (E.g. context returned by external function) can't be analyzed and Walk returns UnsupportedError.
+type visitorPlugin struct {
+	detached bool
+
+	// enclosing holds the function of the call and its enclosing functions, whose ctx parameters are derived.
+	enclosing map[*ssa.Function]struct{}
+
+	// seen holds calls that are already walked to stop infinite recursion.
+	// E.g. for { ctx = wrap(ctx) }
+	seen map[ssa.Value]struct{}
+
+	factWrapper *factutil.FactWrapper[*detachesContext]
+}
+
+func newVisitorPlugin(fn *ssa.Function, factWrapper *factutil.FactWrapper[*detachesContext]) *visitorPlugin {
+	enclosing := make(map[*ssa.Function]struct{})
+	for ; fn != nil; fn = fn.Parent() {
+		enclosing[fn] = struct{}{}
+	}
+	return &visitorPlugin{
+		enclosing:   enclosing,
+		seen:        make(map[ssa.Value]struct{}),
+		factWrapper: factWrapper,
+	}
+}
+
+func (p *visitorPlugin) VisitConst(val *ssa.Const) error {
+	if val.IsNil() {
+		// passing nil as context.
+		p.detached = true
+	}
+	return nil
+}
+
+//nolint:cyclop // one case per kind of call.
+func (p *visitorPlugin) VisitCall(val *ssa.Call) error {
+	if _, ok := p.seen[val]; ok {
+		return nil
+	}
+	p.seen[val] = struct{}{}
+
+	callee := val.Call.StaticCallee()
+	if isRootContext(callee) {
+		p.detached = true
+		return nil
+	}
+	// context returned by the call is derived from its context arguments.
+	// E.g. ctx, cancel := context.WithTimeout(parent, time.Second)
+	var hasContextArg bool
+	for _, arg := range val.Call.Args {
+		if !isContextType(arg.Type()) {
+			continue
+		}
+		hasContextArg = true
+		if err := p.walk(arg); err != nil {
+			return err
+		}
+	}
+	if hasContextArg {
+		return nil
+	}
+	if callee != nil {
+		if _, ok := p.factWrapper.Import(callee); ok {
+			p.detached = true
+			return nil
+		}
+	}
+	if recv := receiver(&val.Call); recv != nil {
+		// E.g. s.ctx(), req.Context(). The context is derived if the receiver is.
+		return p.walkReceiver(val, recv)
+	}
+	if callee == nil || len(callee.Blocks) == 0 {
+		return &ssawalk.UnsupportedError{Value: val, Reason: "context returned by unknown function"}
+	}
+	// E.g. newContext() creates context by itself.
+	for _, value := range returnedContexts(callee) {
+		if err := p.walk(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VisitComplex handles values whose sources are not walked. E.g. ctx parameter, globals, fields.
+func (p *visitorPlugin) VisitComplex(val ssa.Value) error {
+	switch val := val.(type) {
+	case *ssa.Parameter:
+		if _, ok := p.enclosing[val.Parent()]; !ok || !isContextType(val.Type()) {
+			p.detached = true
+		}
+		return nil
+	case *ssa.Global, *ssa.FieldAddr, *ssa.Field:
+		// E.g. ctx stored to struct field by constructor.
+		p.detached = true
+		return nil
+	}
+	return &ssawalk.UnsupportedError{Value: val, Reason: "sources of context"}
+}
+
+// VisitAlloc handles variables whose stores can't be tracked. E.g. &ctx is passed to other function.
+func (p *visitorPlugin) VisitAlloc(val *ssa.Alloc) error {
+	return &ssawalk.UnsupportedError{Value: val, Reason: "stores to context variable"}
+}
+
+// walk walks sources of the context val.
+func (p *visitorPlugin) walk(val ssa.Value) error {
+	return ssawalk.Walk(ssawalk.NewDefaultVisitorWith(p.createOptions()...), val)
+}
+
+// walkReceiver walks sources of the receiver of call. The receiver must reach parameters of the enclosing functions.
+func (p *visitorPlugin) walkReceiver(call *ssa.Call, recv ssa.Value) error {
+	unsupported := &ssawalk.UnsupportedError{Value: call, Reason: "context returned by method of untrackable receiver"}
+	return ssawalk.Walk(ssawalk.NewDefaultVisitorWith(
+		ssawalk.WithAnyType(),
+		ssawalk.WithVisitConst(func(*ssa.Const) error { return unsupported }),
+		ssawalk.WithVisitAlloc(func(*ssa.Alloc) error { return unsupported }),
+		ssawalk.WithVisitCall(func(*ssa.Call) error { return unsupported }),
+		ssawalk.WithVisitCallInvoke(func(*ssa.Call) error { return unsupported }),
+		ssawalk.WithVisitComplex(func(val ssa.Value) error {
+			if param, ok := val.(*ssa.Parameter); ok {
+				if _, ok := p.enclosing[param.Parent()]; ok {
+					return nil
+				}
+			}
+			return unsupported
+		}),
+	), recv)
+}
+
+func (p *visitorPlugin) createOptions() []ssawalk.Option {
+	return []ssawalk.Option{
+		ssawalk.WithAnyType(),
+		ssawalk.WithVisitConst(p.VisitConst),
+		ssawalk.WithVisitAlloc(p.VisitAlloc),
+		ssawalk.WithVisitCall(p.VisitCall),
+		ssawalk.WithVisitCallInvoke(p.VisitCall),
+		ssawalk.WithVisitComplex(p.VisitComplex),
+	}
+}
@@ -0,0 +1,80 @@
+package errdetails
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// getNewErrorDetails returns all connect.NewErrorDetail calls in fn.
+func getNewErrorDetails(fn *ssa.Function) []*ssa.Call {
+	var calls []*ssa.Call
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+			if isConnectNewErrorDetail(call.Call.StaticCallee()) {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// isErrorHandled returns true if the error returned by connect.NewErrorDetail is used.
+// detail, err := connect.NewErrorDetail(msg) <- err is used later
+// detail, _ := connect.NewErrorDetail(msg)   <- ssa doesn't create Extract for `_`
+func isErrorHandled(call *ssa.Call) bool {
+	refs := call.Referrers()
+	if refs == nil {
+		return false
+	}
+	for _, ref := range *refs {
+		extract, ok := ref.(*ssa.Extract)
+		if !ok || extract.Index != 1 {
+			continue
+		}
+		if extractRefs := extract.Referrers(); extractRefs != nil && len(*extractRefs) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getDetailType returns the concrete type of the message passed to connect.NewErrorDetail.
+// If the message is already interface (proto.Message), returns false because we can't know the concrete type.
+func getDetailType(call *ssa.Call) (types.Type, bool) {
+	if len(call.Call.Args) != 1 {
+		return nil, false
+	}
+	mi, ok := call.Call.Args[0].(*ssa.MakeInterface)
+	if !ok {
+		return nil, false
+	}
+	return mi.X.Type(), true
+}
+
+// getPackagePath returns the package path that defines typ.
+func getPackagePath(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return named.Obj().Pkg().Path()
+}
+
+// isConnectNewErrorDetail returns true if fn is connect.NewErrorDetail.
+func isConnectNewErrorDetail(fn *ssa.Function) bool {
+	if fn == nil || fn.Pkg == nil || fn.Pkg.Pkg == nil {
+		return false
+	}
+	path := fn.Pkg.Pkg.Path()
+	const connectPath = "connectrpc.com/connect"
+	return (path == connectPath || strings.HasSuffix(path, "vendor/"+connectPath)) && fn.Name() == "NewErrorDetail"
+}
@@ -0,0 +1,122 @@
+package errdetails
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"log/slog"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+const (
+	doc                 = "rpc_errdetails checks if connect.Error details are created properly."
+	reportMsgUnhandled  = "func %s ignores error returned by connect.NewErrorDetail"
+	reportMsgNotAllowed = "func %s uses error detail %s that is not defined in DetailPackages"
+)
+
+// Analyzer checks if connect.Error details are created properly.
+var Analyzer = &analysis.Analyzer{
+	Name: "rpc_errdetails",
+	Doc:  doc,
+	Run:  setupAndRun,
+	Requires: []*analysis.Analyzer{
+		buildssa.Analyzer,
+	},
+	Flags: *flag.NewFlagSet("rpc_errdetails", flag.ExitOnError),
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
+	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
+	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
+	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
+	Analyzer.Flags.StringVar(&DetailPackages, "DetailPackages", DetailPackages, "packages that can define error detail messages")
+}
+
+func setupAndRun(pass *analysis.Pass) (any, error) {
+	log, closer, err := logger.New(LogConfig, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := closer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	// any files that are not excluded are target.
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	detailFilter, err := filter.New(DetailPackages, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return run(pass, log, fileFilter, detailFilter)
+}
+
+func run(pass *analysis.Pass, log *slog.Logger, fileFilter, detailFilter *filter.Filter) (interface{}, error) {
+	log.Debug("analyzing package")
+
+	// Phase 1: Get SSA
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		panic("failed to get SSA")
+	}
+
+	// Phase 2: Func is target?
+	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, srcFunc := range ssaData.SrcFuncs {
+		if isTargetFunc(pass, log, fileFilter, srcFunc) {
+			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
+		}
+	}
+
+	// Phase 3: Check connect.NewErrorDetail calls.
+	// Error details are usually created in helpers, so all funcs are checked not only RPC methods.
+	for _, srcFunc := range targetSrcFuncs {
+		for _, call := range getNewErrorDetails(srcFunc) {
+			if !isErrorHandled(call) {
+				pass.Reportf(call.Pos(), reportMsgUnhandled, srcFunc.Name())
+			}
+			typ, ok := getDetailType(call)
+			if !ok {
+				// message type is unknown, we can't analyze further.
+				continue
+			}
+			if !detailFilter.IsTarget(getPackagePath(typ)) {
+				pass.Reportf(call.Pos(), reportMsgNotAllowed, srcFunc.Name(), types.TypeString(typ, nil))
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter, srcFunc *ssa.Function) bool {
+	if srcFunc == nil {
+		panic("srcFunc is nil")
+	}
+	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
+	if !fileFilter.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+		return false
+	}
+	if SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos()) {
+		log.Debug("skip Function (generated file)", logger.Attr(srcFunc))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package errdetails_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
+	"github.com/cloverrose/rpcguard/passes/errdetails"
+)
+
+func Test(t *testing.T) {
+	t.Parallel()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &errdetails.LogConfig.Level, "INFO")
+	configtest.Set(t, &errdetails.DetailPackages, `google\.golang\.org/genproto/googleapis/rpc/errdetails,a/allowed$`)
+	pkgs := "a"
+	analysistest.Run(t, testdata, errdetails.Analyzer, strings.Split(pkgs, ",")...)
+}
@@ -0,0 +1,69 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"a/allowed"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// InternalDetail is error detail message defined in internal-only proto.
+type InternalDetail struct {
+	Trace string
+}
+
+func (m *InternalDetail) ProtoReflect() protoreflect.Message {
+	panic("implement me")
+}
+
+func (app *App) AddAllowedDetail(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	connectErr := connect.NewError(connect.CodeInvalidArgument, errors.New("invalid"))
+	detail, err := connect.NewErrorDetail(&allowed.BadRequest{Field: "text"})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	connectErr.AddDetail(detail)
+	return nil, connectErr
+}
+
+func (app *App) IgnoreError(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	connectErr := connect.NewError(connect.CodeInvalidArgument, errors.New("invalid"))
+	detail, _ := connect.NewErrorDetail(&allowed.BadRequest{Field: "text"}) // want "func IgnoreError ignores error returned by connect.NewErrorDetail"
+	connectErr.AddDetail(detail)
+	return nil, connectErr
+}
+
+func (app *App) AddInternalDetail(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	connectErr := connect.NewError(connect.CodeInternal, errors.New("internal"))
+	detail, err := connect.NewErrorDetail(&InternalDetail{Trace: "trace"}) // want `func AddInternalDetail uses error detail \*a.InternalDetail that is not defined in DetailPackages`
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	connectErr.AddDetail(detail)
+	return nil, connectErr
+}
+
+// withDetail is helper that attaches detail to connectErr.
+func withDetail(connectErr *connect.Error, msg proto.Message) *connect.Error {
+	if detail, err := connect.NewErrorDetail(msg); err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
+
+// withInternalDetail is helper that attaches internal detail and ignores error.
+func withInternalDetail(connectErr *connect.Error) *connect.Error {
+	detail, _ := connect.NewErrorDetail(&InternalDetail{Trace: "trace"}) // want "func withInternalDetail ignores error returned by connect.NewErrorDetail" `func withInternalDetail uses error detail \*a.InternalDetail that is not defined in DetailPackages`
+	connectErr.AddDetail(detail)
+	return connectErr
+}
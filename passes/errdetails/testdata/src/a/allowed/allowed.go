@@ -0,0 +1,14 @@
+package allowed
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// BadRequest is error detail message that can be sent to clients.
+type BadRequest struct {
+	Field string
+}
+
+func (m *BadRequest) ProtoReflect() protoreflect.Message {
+	panic("implement me")
+}
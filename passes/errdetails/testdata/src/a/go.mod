@@ -0,0 +1,8 @@
+module a
+
+go 1.24.6
+
+require (
+	connectrpc.com/connect v1.18.1
+	google.golang.org/protobuf v1.36.7
+)
@@ -0,0 +1,198 @@
+package headers
+
+import (
+	"go/constant"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	connectPath = "connectrpc.com/connect"
+	httpPath    = "net/http"
+
+	getterResponseHeader = "ResponseHeader"
+)
+
+// headerWrite is a write to the header returned by connect.
+// E.g. res.Header().Set(key, value), connectErr.Meta().Add(key, value), res.Trailer()[key] = values
+type headerWrite struct {
+	instr  ssa.Instruction // call of http.Header.Set/Add or map update.
+	getter string          // name of the method that returns the header. E.g. Header, Trailer, Meta, ResponseHeader.
+	key    string          // written key. empty if key is not constant.
+	value  ssa.Value       // written value. nil if the write is map update.
+}
+
+// getHeaderWrites returns all writes to the headers returned by connect in fn.
+func getHeaderWrites(fn *ssa.Function) []*headerWrite {
+	var writes []*headerWrite
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Call:
+				callee := instr.Call.StaticCallee()
+				if !isHeaderMethod(callee, "Set") && !isHeaderMethod(callee, "Add") {
+					continue
+				}
+				// Args should be [receiver, key, value]
+				getter, ok := getGetterName(instr.Call.Args[0])
+				if !ok {
+					continue
+				}
+				writes = append(writes, &headerWrite{
+					instr:  instr,
+					getter: getter,
+					key:    constString(instr.Call.Args[1]),
+					value:  instr.Call.Args[2],
+				})
+			case *ssa.MapUpdate:
+				getter, ok := getGetterName(instr.Map)
+				if !ok {
+					continue
+				}
+				writes = append(writes, &headerWrite{
+					instr:  instr,
+					getter: getter,
+					key:    constString(instr.Key),
+				})
+			}
+		}
+	}
+	return writes
+}
+
+// getSends returns all stream.Send() calls in fn.
+func getSends(fn *ssa.Function) []*ssa.Call {
+	var sends []*ssa.Call
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+			if isConnectMethod(call.Call.StaticCallee(), "Send", "ServerStream", "BidiStream") {
+				sends = append(sends, call)
+			}
+		}
+	}
+	return sends
+}
+
+// getGetterName returns the name of connect method that returns header value.
+func getGetterName(val ssa.Value) (string, bool) {
+	call, ok := val.(*ssa.Call)
+	if !ok {
+		return "", false
+	}
+	callee := call.Call.StaticCallee()
+	switch {
+	case isConnectMethod(callee, "Header", "Response"),
+		isConnectMethod(callee, "Trailer", "Response"),
+		isConnectMethod(callee, "Meta", "Error"),
+		isConnectMethod(callee, getterResponseHeader, "ServerStream", "BidiStream"),
+		isConnectMethod(callee, "ResponseTrailer", "ServerStream", "BidiStream"):
+		_, name, _ := funcInfo(callee)
+		return name, true
+	default:
+		return "", false
+	}
+}
+
+// isBinaryValue returns true if value is non-ASCII constant string or encoded by connect.EncodeBinaryHeader.
+func isBinaryValue(value ssa.Value) bool {
+	if value == nil {
+		return false
+	}
+	if call, ok := value.(*ssa.Call); ok {
+		path, name, _ := funcInfo(call.Call.StaticCallee())
+		return isConnectPath(path) && name == "EncodeBinaryHeader"
+	}
+	str := constString(value)
+	for i := range len(str) {
+		if str[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// isReachable returns true if to can be executed after from.
+func isReachable(from, to ssa.Instruction) bool {
+	if from.Block() == to.Block() && slices.Index(from.Block().Instrs, from) < slices.Index(to.Block().Instrs, to) {
+		return true
+	}
+	visited := make(map[*ssa.BasicBlock]bool)
+	queue := slices.Clone(from.Block().Succs)
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		if visited[block] {
+			continue
+		}
+		visited[block] = true
+		if block == to.Block() {
+			return true
+		}
+		queue = append(queue, block.Succs...)
+	}
+	return false
+}
+
+// constString returns string value if val is constant string.
+func constString(val ssa.Value) string {
+	c, ok := val.(*ssa.Const)
+	if !ok || c.Value == nil || c.Value.Kind() != constant.String {
+		return ""
+	}
+	return constant.StringVal(c.Value)
+}
+
+// isHeaderMethod returns true if fn is (net/http.Header).name.
+func isHeaderMethod(fn *ssa.Function, name string) bool {
+	path, fnName, recv := funcInfo(fn)
+	return path == httpPath && fnName == name && recv == "Header"
+}
+
+// isConnectMethod returns true if fn is connect method that has given name and receiver.
+func isConnectMethod(fn *ssa.Function, name string, recvNames ...string) bool {
+	path, fnName, recv := funcInfo(fn)
+	return isConnectPath(path) && fnName == name && slices.Contains(recvNames, recv)
+}
+
+func isConnectPath(path string) bool {
+	return path == connectPath || strings.HasSuffix(path, "vendor/"+connectPath)
+}
+
+// funcInfo returns package path, name and receiver type name of fn.
+// It uses types.Func because methods of imported types and instantiated functions don't have Pkg.
+// E.g. (net/http.Header).Set, (*connect.Response[T]).Header[a.Message]
+func funcInfo(fn *ssa.Function) (path, name, recv string) {
+	if fn == nil {
+		return "", "", ""
+	}
+	obj, ok := fn.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return "", "", ""
+	}
+	return obj.Pkg().Path(), obj.Name(), recvName(obj.Signature())
+}
+
+// recvName returns the type name of sig's receiver.
+func recvName(sig *types.Signature) string {
+	recv := sig.Recv()
+	if recv == nil {
+		return ""
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name()
+}
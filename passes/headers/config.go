@@ -0,0 +1,61 @@
+package headers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+// log related configuration. See logger.Config.
+var LogConfig = logger.Config{
+	Level:  "WARN",
+	File:   "",
+	Dir:    "",
+	Format: "json",
+}
+
+// ExcludeFiles is configuration which files should be excluded.
+// This is useful to exclude test file, generated files.
+// To set the same value with the default config, use this command line argument.
+// -rpc_headers.ExcludeFiles='.+_test\.go,.+\.connect\.go'
+var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
+
+// SkipGenerated is configuration whether skip functions in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Default is true.
+var SkipGenerated = true
+
+// ReservedHeaders is configuration which header keys are reserved by protocol.
+// Default is "Content-Type,Grpc-*,Connect-*"
+// Keys are compared in canonical form, and key ends with `*` matches keys that have the prefix.
+// You can specify multiple keys by using `,` separated value.
+var ReservedHeaders = "Content-Type,Grpc-*,Connect-*"
+
+type reservedHeader struct {
+	key    string
+	prefix bool
+}
+
+func parseReservedHeaders(input string) []reservedHeader {
+	values := strings.Split(input, ",")
+	headers := make([]reservedHeader, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if key, ok := strings.CutSuffix(value, "*"); ok {
+			headers = append(headers, reservedHeader{http.CanonicalHeaderKey(key), true})
+			continue
+		}
+		headers = append(headers, reservedHeader{http.CanonicalHeaderKey(value), false})
+	}
+	return headers
+}
+
+func (h reservedHeader) match(key string) bool {
+	if h.prefix {
+		return strings.HasPrefix(key, h.key)
+	}
+	return key == h.key
+}
@@ -0,0 +1,166 @@
+package headers
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+const (
+	doc                   = "rpc_headers checks if RPC method sets response headers and trailers properly."
+	reportMsgReserved     = "RPC method %s sets reserved header %q"
+	reportMsgNonCanonical = "RPC method %s sets non-canonical header %q, use %q"
+	reportMsgBinary       = "RPC method %s sets binary header %q without -Bin suffix"
+	reportMsgAfterSend    = "RPC method %s sets response header after sending message"
+)
+
+// Analyzer checks if RPC method sets response headers and trailers properly.
+var Analyzer = &analysis.Analyzer{
+	Name: "rpc_headers",
+	Doc:  doc,
+	Run:  setupAndRun,
+	Requires: []*analysis.Analyzer{
+		buildssa.Analyzer,
+	},
+	Flags: *flag.NewFlagSet("rpc_headers", flag.ExitOnError),
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
+	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
+	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
+	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
+	Analyzer.Flags.StringVar(&ReservedHeaders, "ReservedHeaders", ReservedHeaders, "reserved header keys")
+}
+
+func setupAndRun(pass *analysis.Pass) (any, error) {
+	log, closer, err := logger.New(LogConfig, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := closer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	// any files that are not excluded are target.
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(pass, log, fileFilter)
+}
+
+func run(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter) (interface{}, error) {
+	log.Debug("analyzing package")
+
+	// Phase 1: Get SSA
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		panic("failed to get SSA")
+	}
+
+	// Phase 2: Func is target?
+	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, srcFunc := range ssaData.SrcFuncs {
+		if isTargetFunc(pass, log, fileFilter, srcFunc) {
+			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
+		}
+	}
+
+	// Phase 3: Func is RPC method?.
+	rpcAnalyzer := rpcmethod.BuildChecker(pass)
+	if rpcAnalyzer == nil {
+		log.Debug("skip package (no rpc method types)")
+		return nil, nil
+	}
+	rpcMethods := make([]*ssa.Function, 0, len(targetSrcFuncs))
+	streamMethods := make([]*ssa.Function, 0, len(targetSrcFuncs))
+	for _, fn := range targetSrcFuncs {
+		if rpcAnalyzer.IsRPCMethod(fn) {
+			rpcMethods = append(rpcMethods, fn)
+		}
+		if rpcAnalyzer.IsStreamRPCMethod(fn) {
+			streamMethods = append(streamMethods, fn)
+		}
+	}
+
+	// Phase 4: Check header keys and values
+	reservedHeaders := parseReservedHeaders(ReservedHeaders)
+	for _, srcFunc := range slices.Concat(rpcMethods, streamMethods) {
+		for _, write := range getHeaderWrites(srcFunc) {
+			checkHeaderWrite(pass, srcFunc, write, reservedHeaders)
+		}
+	}
+
+	// Phase 5: Check response header is set before sending message
+	for _, srcFunc := range streamMethods {
+		checkHeaderAfterSend(pass, srcFunc)
+	}
+
+	return nil, nil
+}
+
+func checkHeaderWrite(pass *analysis.Pass, fn *ssa.Function, write *headerWrite, reservedHeaders []reservedHeader) {
+	if write.key == "" {
+		// key is not constant, we can't analyze further.
+		return
+	}
+	canonical := http.CanonicalHeaderKey(write.key)
+	if slices.ContainsFunc(reservedHeaders, func(h reservedHeader) bool { return h.match(canonical) }) {
+		pass.Reportf(write.instr.Pos(), reportMsgReserved, fn.Name(), write.key)
+		return
+	}
+	if write.key != canonical {
+		pass.Reportf(write.instr.Pos(), reportMsgNonCanonical, fn.Name(), write.key, canonical)
+	}
+	if isBinaryValue(write.value) && !strings.HasSuffix(canonical, "-Bin") {
+		pass.Reportf(write.instr.Pos(), reportMsgBinary, fn.Name(), write.key)
+	}
+}
+
+// checkHeaderAfterSend reports response header writes that can be executed after stream.Send().
+// connect sends response header with the first message, then later writes are ignored.
+func checkHeaderAfterSend(pass *analysis.Pass, fn *ssa.Function) {
+	sends := getSends(fn)
+	for _, write := range getHeaderWrites(fn) {
+		if write.getter != getterResponseHeader {
+			continue
+		}
+		if slices.ContainsFunc(sends, func(send *ssa.Call) bool { return isReachable(send, write.instr) }) {
+			pass.Reportf(write.instr.Pos(), reportMsgAfterSend, fn.Name())
+		}
+	}
+}
+
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter, srcFunc *ssa.Function) bool {
+	if srcFunc == nil {
+		panic("srcFunc is nil")
+	}
+	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
+	if !fileFilter.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+		return false
+	}
+	if SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos()) {
+		log.Debug("skip Function (generated file)", logger.Attr(srcFunc))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package headers_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
+	"github.com/cloverrose/rpcguard/passes/headers"
+)
+
+func Test(t *testing.T) {
+	t.Parallel()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &headers.LogConfig.Level, "INFO")
+	pkgs := "a"
+	analysistest.Run(t, testdata, headers.Analyzer, strings.Split(pkgs, ",")...)
+}
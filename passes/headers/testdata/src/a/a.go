@@ -0,0 +1,89 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+func (app *App) SetCustomHeader(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	res := connect.NewResponse(&Message{"hello"})
+	res.Header().Set("X-Request-Id", req.Msg.text)
+	res.Header().Add("X-Custom", "value")
+	res.Trailer().Set("X-Elapsed", "1s")
+	res.Header().Set("X-Payload-Bin", connect.EncodeBinaryHeader([]byte("payload")))
+	return res, nil
+}
+
+func (app *App) SetReservedHeader(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	res := connect.NewResponse(&Message{"hello"})
+	res.Header().Set("Content-Type", "application/json") // want `RPC method SetReservedHeader sets reserved header "Content-Type"`
+	res.Header().Set("grpc-status", "0")                 // want `RPC method SetReservedHeader sets reserved header "grpc-status"`
+	res.Trailer().Add("Connect-Timeout-Ms", "1000")      // want `RPC method SetReservedHeader sets reserved header "Connect-Timeout-Ms"`
+	return res, nil
+}
+
+func (app *App) SetNonCanonicalHeader(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	res := connect.NewResponse(&Message{"hello"})
+	res.Header().Set("x-request-id", req.Msg.text) // want `RPC method SetNonCanonicalHeader sets non-canonical header "x-request-id", use "X-Request-Id"`
+	res.Trailer()["x-elapsed"] = []string{"1s"}    // want `RPC method SetNonCanonicalHeader sets non-canonical header "x-elapsed", use "X-Elapsed"`
+	return res, nil
+}
+
+func (app *App) SetBinaryHeader(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	res := connect.NewResponse(&Message{"hello"})
+	res.Header().Set("X-Name", "こんにちは")                                          // want `RPC method SetBinaryHeader sets binary header "X-Name" without -Bin suffix`
+	res.Header().Set("X-Payload", connect.EncodeBinaryHeader([]byte("payload"))) // want `RPC method SetBinaryHeader sets binary header "X-Payload" without -Bin suffix`
+	return res, nil
+}
+
+func (app *App) SetErrorMeta(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	connectErr := connect.NewError(connect.CodeInternal, errors.New("err"))
+	connectErr.Meta().Set("X-Retry-After", "1s")
+	connectErr.Meta().Set("Grpc-Message", "err") // want `RPC method SetErrorMeta sets reserved header "Grpc-Message"`
+	return nil, connectErr
+}
+
+func (app *App) StreamHeaderBeforeSend(ctx context.Context, req *connect.Request[Message], stream *connect.ServerStream[Message]) error { // OK
+	stream.ResponseHeader().Set("X-Request-Id", req.Msg.text)
+	for range 3 {
+		if err := stream.Send(&Message{"hello"}); err != nil {
+			return err
+		}
+	}
+	stream.ResponseTrailer().Set("X-Count", "3")
+	return nil
+}
+
+func (app *App) StreamHeaderAfterSend(ctx context.Context, req *connect.Request[Message], stream *connect.ServerStream[Message]) error {
+	if err := stream.Send(&Message{"hello"}); err != nil {
+		return err
+	}
+	stream.ResponseHeader().Set("X-Request-Id", req.Msg.text) // want `RPC method StreamHeaderAfterSend sets response header after sending message`
+	return nil
+}
+
+func (app *App) BidiStreamHeaderInLoop(ctx context.Context, stream *connect.BidiStream[Message, Message]) error {
+	for {
+		msg, err := stream.Receive()
+		if err != nil {
+			return err
+		}
+		stream.ResponseHeader().Set("X-Last", msg.text) // want `RPC method BidiStreamHeaderInLoop sets response header after sending message`
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// notRPCMethod is not RPC method, so it is not reported.
+func notRPCMethod(res *connect.Response[Message]) {
+	res.Header().Set("Content-Type", "application/json")
+}
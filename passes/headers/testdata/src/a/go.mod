@@ -0,0 +1,7 @@
+module a
+
+go 1.24.6
+
+require connectrpc.com/connect v1.18.1
+
+require google.golang.org/protobuf v1.36.7 // indirect
@@ -0,0 +1,47 @@
+package nilresponse
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/rtn"
+)
+
+const (
+	responseIndex = 0
+	errorIndex    = 1
+)
+
+// getNilReturns returns returns of RPC method fn that can return nil response with nil error.
+func getNilReturns(fn *ssa.Function) ([]*ssa.Return, error) {
+	var nilReturns []*ssa.Return
+	for _, val := range rtn.GetReturnsAt(fn, []int{responseIndex}) {
+		res, err := scanVal(val.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !res.hasNil {
+			// response is never nil.
+			continue
+		}
+		errRes, err := scanVal(val.Return.Results[errorIndex])
+		if err != nil {
+			return nil, err
+		}
+		if errRes.hasNil && !errRes.hasNonNil {
+			// error is proven nil.
+			nilReturns = append(nilReturns, val.Return)
+		}
+	}
+	return nilReturns, nil
+}
+
+// scanVal scans value.
+func scanVal(val ssa.Value) (*visitorPlugin, error) {
+	plugin := &visitorPlugin{}
+	if err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(plugin.createOptions()...), val); err != nil {
+		return nil, err
+	}
+	return plugin, nil
+}
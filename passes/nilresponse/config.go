@@ -0,0 +1,22 @@
+package nilresponse
+
+import "github.com/cloverrose/rpcguard/pkg/logger"
+
+// log related configuration. See logger.Config.
+var LogConfig = logger.Config{
+	Level:  "WARN",
+	File:   "",
+	Dir:    "",
+	Format: "json",
+}
+
+// ExcludeFiles is configuration which files should be excluded.
+// This is useful to exclude test file, generated files.
+// To set the same value with the default config, use this command line argument.
+// -rpc_nilresponse.ExcludeFiles='.+_test\.go,.+\.connect\.go'
+var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
+
+// SkipGenerated is configuration whether skip functions in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Default is true.
+var SkipGenerated = true
@@ -0,0 +1,128 @@
+package nilresponse
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+const (
+	doc       = "rpc_nilresponse checks if RPC method returns nil response together with nil error."
+	reportMsg = "RPC method %s returns nil response with nil error"
+)
+
+// Analyzer checks if RPC method returns nil response together with nil error.
+var Analyzer = &analysis.Analyzer{
+	Name: "rpc_nilresponse",
+	Doc:  doc,
+	Run:  setupAndRun,
+	Requires: []*analysis.Analyzer{
+		buildssa.Analyzer,
+	},
+	Flags: *flag.NewFlagSet("rpc_nilresponse", flag.ExitOnError),
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
+	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
+	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
+	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
+}
+
+func setupAndRun(pass *analysis.Pass) (any, error) {
+	log, closer, err := logger.New(LogConfig, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := closer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	// any files that are not excluded are target.
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(pass, log, fileFilter)
+}
+
+func run(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter) (interface{}, error) {
+	log.Debug("analyzing package")
+
+	// Phase 1: Get SSA
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		panic("failed to get SSA")
+	}
+	defer ssawalk.IndexFields(ssaData.Pkg.Prog)()
+
+	// Phase 2: Func is target?
+	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, srcFunc := range ssaData.SrcFuncs {
+		if isTargetFunc(pass, log, fileFilter, srcFunc) {
+			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
+		}
+	}
+
+	// Phase 3: Func is RPC method?.
+	rpcAnalyzer := rpcmethod.BuildChecker(pass)
+	if rpcAnalyzer == nil {
+		log.Debug("skip package (no rpc method types)")
+		return nil, nil
+	}
+	rpcMethods := make([]*ssa.Function, 0, len(targetSrcFuncs))
+	for _, fn := range targetSrcFuncs {
+		if rpcAnalyzer.IsRPCMethod(fn) {
+			rpcMethods = append(rpcMethods, fn)
+		}
+	}
+
+	// Phase 4: Check returns
+	for _, srcFunc := range rpcMethods {
+		nilReturns, err := getNilReturns(srcFunc)
+		if err != nil {
+			if !ssawalk.IsUnsupported(err) {
+				return nil, err
+			}
+			// can't analyze the method, report it instead of judging it ok.
+			rpcmethod.ReportNotAnalyzed(pass, srcFunc, err)
+			continue
+		}
+		for _, rtn := range nilReturns {
+			pass.Reportf(rtn.Pos(), reportMsg, srcFunc.Name())
+		}
+	}
+
+	return nil, nil
+}
+
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, fileFilter *filter.Filter, srcFunc *ssa.Function) bool {
+	if srcFunc == nil {
+		panic("srcFunc is nil")
+	}
+	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
+	if !fileFilter.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+		return false
+	}
+	if SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos()) {
+		log.Debug("skip Function (generated file)", logger.Attr(srcFunc))
+		return false
+	}
+	return true
+}
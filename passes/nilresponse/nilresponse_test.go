@@ -0,0 +1,23 @@
+package nilresponse_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
+	"github.com/cloverrose/rpcguard/passes/nilresponse"
+)
+
+func Test(t *testing.T) {
+	t.Parallel()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &nilresponse.LogConfig.Level, "INFO")
+	pkgs := "a"
+	analysistest.Run(t, testdata, nilresponse.Analyzer, strings.Split(pkgs, ",")...)
+}
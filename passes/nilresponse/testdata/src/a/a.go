@@ -0,0 +1,69 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+func (app *App) ReturnResponse(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	return connect.NewResponse(&Message{"hello"}), nil
+}
+
+func (app *App) ReturnError(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	return nil, connect.NewError(connect.CodeInternal, errors.New("err"))
+}
+
+func (app *App) ReturnNilNil(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	return nil, nil // want "RPC method ReturnNilNil returns nil response with nil error"
+}
+
+func (app *App) ReturnNilNilInBranch(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	if req.Msg.text == "" {
+		return nil, nil // want "RPC method ReturnNilNilInBranch returns nil response with nil error"
+	}
+	return connect.NewResponse(&Message{"hello"}), nil
+}
+
+func (app *App) ReturnNilNilViaVariables(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	var res *connect.Response[Message]
+	var err error
+	if req.Msg.text == "" {
+		err = nil
+	}
+	return res, err // want "RPC method ReturnNilNilViaVariables returns nil response with nil error"
+}
+
+func (app *App) ReturnMaybeNilResponse(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	var res *connect.Response[Message]
+	if req.Msg.text != "" {
+		res = connect.NewResponse(&Message{"hello"})
+	}
+	return res, nil // want "RPC method ReturnMaybeNilResponse returns nil response with nil error"
+}
+
+func (app *App) ReturnNilResponseWithMaybeNilError(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	var err error
+	if req.Msg.text == "" {
+		err = connect.NewError(connect.CodeInvalidArgument, errors.New("err"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"hello"}), nil
+}
+
+func (app *App) ReturnCallResult(ctx context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // OK
+	return app.do(ctx)
+}
+
+func (app *App) do(ctx context.Context) (*connect.Response[Message], error) {
+	return nil, nil
+}
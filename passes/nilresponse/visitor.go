@@ -0,0 +1,39 @@
+package nilresponse
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+)
+
+// visitorPlugin visit ssa.Value and records whether sources of the value are nil constant or not.
+// Sources that can't be analyzed further (call, parameter, alloc etc.) are treated as non nil.
+type visitorPlugin struct {
+	hasNil    bool // at least one source is nil constant.
+	hasNonNil bool // at least one source is not nil constant.
+}
+
+func (p *visitorPlugin) VisitConst(val *ssa.Const) error {
+	if val.IsNil() {
+		p.hasNil = true
+	} else {
+		p.hasNonNil = true
+	}
+	return nil
+}
+
+func (p *visitorPlugin) visitNonNil(_ ssa.Value) error {
+	p.hasNonNil = true
+	return nil
+}
+
+func (p *visitorPlugin) createOptions() []ssawalk.Option {
+	return []ssawalk.Option{
+		ssawalk.WithVisitConst(p.VisitConst),
+		ssawalk.WithVisitFunction(func(val *ssa.Function) error { return p.visitNonNil(val) }),
+		ssawalk.WithVisitAlloc(func(val *ssa.Alloc) error { return p.visitNonNil(val) }),
+		ssawalk.WithVisitComplex(p.visitNonNil),
+		ssawalk.WithVisitCall(func(val *ssa.Call) error { return p.visitNonNil(val) }),
+		ssawalk.WithVisitCallInvoke(func(val *ssa.Call) error { return p.visitNonNil(val) }),
+	}
+}
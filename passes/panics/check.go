@@ -0,0 +1,135 @@
+package panics
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	logPath = "log"
+	osPath  = "os"
+)
+
+// knownFuncs is functions whose behavior is configured instead of analyzed by their body,
+// because their body is not available (defined in excluded packages) or too complex.
+type knownFuncs struct {
+	recover funcNames // See RecoverFuncs.
+	must    funcNames // See MustFuncs.
+}
+
+// getPanicSource returns the panic source in fn.
+// panic source is panic(...) or call to the function that terminates the process like log.Fatal, os.Exit.
+// Functions in MustFuncs (E.g. regexp.MustCompile) are also treated as panic source because they panic on error.
+func (k *knownFuncs) getPanicSource(fn *ssa.Function) (string, bool) {
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Panic:
+				return "panic", true
+			case ssa.CallInstruction:
+				if _, ok := instr.(*ssa.Go); ok {
+					// panic in goroutine is checked by getGoroutines.
+					continue
+				}
+				callee := instr.Common().StaticCallee()
+				if isTerminateFunc(callee) || k.must.contains(callee) {
+					return callee.String(), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// getCallees returns functions called from fn.
+// Deferred functions are included because they run in fn, but functions started by go statement are not included.
+func getCallees(fn *ssa.Function) []*ssa.Function {
+	var callees []*ssa.Function
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			if _, ok := instr.(*ssa.Go); ok {
+				continue
+			}
+			if callee := call.Common().StaticCallee(); callee != nil {
+				callees = append(callees, callee)
+			}
+		}
+	}
+	return callees
+}
+
+// getGoroutines returns go statements in fn.
+func getGoroutines(fn *ssa.Function) []*ssa.Go {
+	var gos []*ssa.Go
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if g, ok := instr.(*ssa.Go); ok {
+				gos = append(gos, g)
+			}
+		}
+	}
+	return gos
+}
+
+// hasRecover returns true if fn defers a function that calls recover().
+func (k *knownFuncs) hasRecover(fn *ssa.Function) bool {
+	if fn == nil {
+		return false
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			d, ok := instr.(*ssa.Defer)
+			if !ok {
+				continue
+			}
+			if k.isRecoverFunc(d.Call.StaticCallee()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isRecoverFunc returns true if fn calls recover().
+// If fn is defined in other package, its body is not available.
+// In that case, only functions in RecoverFuncs are treated as recover function. E.g. defer recovery.Recover()
+func (k *knownFuncs) isRecoverFunc(fn *ssa.Function) bool {
+	if fn == nil {
+		return false
+	}
+	if k.recover.contains(fn) {
+		return true
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+			if builtin, ok := call.Call.Value.(*ssa.Builtin); ok && builtin.Name() == "recover" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTerminateFunc returns true if fn is log.Fatal*, log.Panic* (including *log.Logger methods) or os.Exit.
+func isTerminateFunc(fn *ssa.Function) bool {
+	if fn == nil || fn.Pkg == nil || fn.Pkg.Pkg == nil {
+		return false
+	}
+	switch fn.Pkg.Pkg.Path() {
+	case logPath:
+		return strings.HasPrefix(fn.Name(), "Fatal") || strings.HasPrefix(fn.Name(), "Panic")
+	case osPath:
+		return fn.Name() == "Exit"
+	default:
+		return false
+	}
+}
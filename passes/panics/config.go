@@ -0,0 +1,105 @@
+package panics
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+// log related configuration. See logger.Config.
+var LogConfig = logger.Config{
+	Level:  "WARN",
+	File:   "",
+	Dir:    "",
+	Format: "json",
+}
+
+// IncludePackages is configuration which packages should be included.
+// Multiple Packages can be specified by using commas (,) or a JSON array.
+// Each pattern is a regexp or a Go package pattern (./..., github.com/foo/bar/...), and "!" negates a pattern.
+// e.g. github.com/foo/bar/a/includedpkg/hello,github.com/foo/bar/common/...,!./internal/mock/...
+// If it is empty, all packages of the main module of the analyzed package are included
+// (and all modules used by go.work if the module is in a workspace), like "$(go list -m)/.*".
+//
+// rpc_panic follows helpers called from RPC method only when the helper is defined in included packages.
+// If RPC method calls helper in excluded package, rpc_panic can't know whether the helper panics or not.
+// Thus, rpc_panic doesn't report such calls except well known functions like log.Fatal, os.Exit and MustFuncs.
+var IncludePackages = ""
+
+// ExcludePackages is configuration which packages should be excluded.
+// This is useful to exclude vendor.
+var ExcludePackages = ""
+
+// ExcludeFiles is configuration which files should be excluded.
+// This is useful to exclude test file, generated files.
+// To set the same value with the default config, use this command line argument.
+// -rpc_panic.ExcludeFiles='.+_test\.go,.+\.connect\.go'
+var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
+
+// SkipGenerated is configuration whether skip RPC methods in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Functions in generated files are still analyzed and their facts are exported, so that callers are judged correctly.
+// Default is true.
+var SkipGenerated = true
+
+// RecoverFuncs is configuration which functions recover panic when deferred.
+// Functions in included packages are analyzed by their body, so this is for functions in excluded packages.
+// E.g. github.com/foo/bar/recovery:Recover for `defer recovery.Recover()`
+// Package and Function (or Method) join with `:`
+// You can specify multiple functions by using `,` separated value.
+var RecoverFuncs = ""
+
+// MustFuncs is configuration which functions panic on error, they are treated as panic source.
+// Default is "regexp:MustCompile,regexp:MustCompilePOSIX,text/template:Must,html/template:Must"
+// Package and Function (or Method) join with `:`
+// You can specify multiple functions by using `,` separated value.
+var MustFuncs = "regexp:MustCompile,regexp:MustCompilePOSIX,text/template:Must,html/template:Must"
+
+// passTargets holds filters built from the configuration for each pass.
+// It is passed explicitly instead of package variables, since passes of packages run concurrently.
+type passTargets struct {
+	packages *filter.Filter
+	files    *filter.Filter
+}
+
+// funcName is a function or method qualified by its package path.
+type funcName struct {
+	packagePath string
+	name        string
+}
+
+type funcNames []funcName
+
+func parseFuncNames(input string) (funcNames, error) {
+	if input == "" {
+		return nil, nil
+	}
+	values := strings.Split(input, ",")
+	names := make(funcNames, len(values))
+	for i, value := range values {
+		parts := strings.Split(value, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid function format: %s", value)
+		}
+		names[i] = funcName{parts[0], parts[1]}
+	}
+	return names, nil
+}
+
+// contains returns true if fn is one of names. Vendored packages match too.
+func (names funcNames) contains(fn *ssa.Function) bool {
+	if fn == nil || fn.Pkg == nil || fn.Pkg.Pkg == nil {
+		return false
+	}
+	path := fn.Pkg.Pkg.Path()
+	for _, name := range names {
+		if (path == name.packagePath || strings.HasSuffix(path, "vendor/"+name.packagePath)) && fn.Name() == name.name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package panics
+
+import (
+	"strings"
+)
+
+// mayPanic is a fact for functions that may panic or exit the process, directly or through its callees.
+type mayPanic struct {
+	// Path is call chain from the function to the panic source.
+	Path []string
+}
+
+func (f *mayPanic) AFact() {}
+
+func (f *mayPanic) String() string {
+	return "mayPanic(" + strings.Join(f.Path, " -> ") + ")"
+}
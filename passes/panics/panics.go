@@ -0,0 +1,200 @@
+package panics
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+	"github.com/cloverrose/rpcguard/pkg/filter"
+	"github.com/cloverrose/rpcguard/pkg/graph"
+	"github.com/cloverrose/rpcguard/pkg/logger"
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+const (
+	doc                = "rpc_panic checks if RPC method may panic or starts goroutine without recover."
+	reportMsg          = "RPC method %s may panic: %s"
+	reportMsgGoroutine = "RPC method %s starts goroutine without recover"
+)
+
+// Analyzer checks if RPC method may panic.
+var Analyzer = &analysis.Analyzer{
+	Name: "rpc_panic",
+	Doc:  doc,
+	Run:  setupAndRun,
+	Requires: []*analysis.Analyzer{
+		buildssa.Analyzer,
+	},
+	Flags: *flag.NewFlagSet("rpc_panic", flag.ExitOnError),
+	FactTypes: []analysis.Fact{
+		&mayPanic{},
+	},
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
+	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
+	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
+	Analyzer.Flags.StringVar(&IncludePackages, "IncludePackages", IncludePackages, "include packages")
+	Analyzer.Flags.StringVar(&ExcludePackages, "ExcludePackages", ExcludePackages, "exclude packages")
+	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
+	Analyzer.Flags.StringVar(&RecoverFuncs, "RecoverFuncs", RecoverFuncs, "functions which recover panic when deferred")
+	Analyzer.Flags.StringVar(&MustFuncs, "MustFuncs", MustFuncs, "functions which panic on error")
+}
+
+func setupAndRun(pass *analysis.Pass) (any, error) {
+	log, closer, err := logger.New(LogConfig, pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := closer(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	packageFilter, _, skipReason, err := filter.ForPass(pass, log, IncludePackages, ExcludePackages)
+	if err != nil {
+		return nil, err
+	}
+	if skipReason != "" {
+		return nil, nil
+	}
+
+	// any files that are not excluded are target.
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	recoverFuncs, err := parseFuncNames(RecoverFuncs)
+	if err != nil {
+		return nil, err
+	}
+	mustFuncs, err := parseFuncNames(MustFuncs)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(pass, log, &passTargets{packages: packageFilter, files: fileFilter}, &knownFuncs{recover: recoverFuncs, must: mustFuncs})
+}
+
+//nolint:gocognit,cyclop // main routine
+func run(pass *analysis.Pass, log *slog.Logger, targets *passTargets, known *knownFuncs) (interface{}, error) {
+	currentPackage := pass.Pkg.Path()
+	log.Debug("analyzing package")
+
+	// Phase 1: Package is target?
+	if !targets.packages.IsTarget(currentPackage) {
+		log.Debug("skip package (not target package)", slog.String("reason", targets.packages.Explain(currentPackage).String()))
+		return nil, nil
+	}
+
+	// Phase 2: Get SSA
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		panic("failed to get SSA")
+	}
+
+	// Phase 3: Func is target?
+	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
+	for _, srcFunc := range ssaData.SrcFuncs {
+		if isTargetFunc(pass, log, targets, srcFunc) {
+			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
+		}
+	}
+
+	// closure func (ends with $1) Object() == nil, then we can't export facts.
+	// Thus, use this localFacts during package check.
+	factWrapper := factutil.NewFactWrapper[*mayPanic](pass)
+
+	// Phase 4: Export facts for funcs that panic by themselves.
+	for _, srcFunc := range targetSrcFuncs {
+		if known.hasRecover(srcFunc) {
+			continue
+		}
+		if source, ok := known.getPanicSource(srcFunc); ok {
+			factWrapper.Export(srcFunc, &mayPanic{Path: []string{srcFunc.String(), source}})
+		}
+	}
+
+	// Phase 5: Create SCCs (this sccs are topologically sorted) and propagate facts to callers.
+	g := graph.NewGraph[*ssa.Function]()
+	for _, srcFunc := range targetSrcFuncs {
+		for _, callee := range getCallees(srcFunc) {
+			g.AddEdge(srcFunc, callee)
+		}
+	}
+	log.Debug("build graph", slog.Any("graph", g))
+	sccs := graph.Decomposition(g)
+	log.Debug("Strongly Connected Components", slog.Any("sccs", graph.SCCs[*ssa.Function](sccs)))
+	newPropagation(known).Run(pass, log, sccs, factWrapper)
+
+	// Phase 6: Check RPC method may panic or not.
+	rpcChecker := rpcmethod.BuildChecker(pass)
+	if rpcChecker == nil {
+		log.Debug("skip package (no rpc method types)")
+		return nil, nil
+	}
+	for _, fn := range targetSrcFuncs {
+		if !rpcChecker.IsRPCMethod(fn) {
+			continue
+		}
+		if SkipGenerated && filter.IsGenerated(pass, fn.Pos()) {
+			log.Debug("skip RPC method (generated file)", logger.Attr(fn))
+			continue
+		}
+		log.Info("found RPC method", logger.Attr(fn))
+		checkRPCMethod(pass, known, factWrapper, fn)
+	}
+
+	return nil, nil
+}
+
+// newPropagation returns propagation of facts from callees to callers.
+// All funcs in the same SCC reach each other, so if one of them may panic, all of them may panic.
+// However, funcs that recover panic stop propagation.
+func newPropagation(known *knownFuncs) *factutil.Propagation[*mayPanic] {
+	return &factutil.Propagation[*mayPanic]{
+		Callees: getCallees,
+		Stops:   known.hasRecover,
+		Derive: func(fn *ssa.Function, source *mayPanic) *mayPanic {
+			return &mayPanic{Path: append([]string{fn.String()}, source.Path...)}
+		},
+	}
+}
+
+// checkRPCMethod reports fn if fn may panic, and go statements in fn that don't recover.
+func checkRPCMethod(pass *analysis.Pass, known *knownFuncs, factWrapper *factutil.FactWrapper[*mayPanic], fn *ssa.Function) {
+	if !known.hasRecover(fn) {
+		if fact, ok := factWrapper.Import(fn); ok {
+			pass.Reportf(fn.Pos(), reportMsg, fn.Name(), strings.Join(fact.Path, " -> "))
+		}
+	}
+	for _, g := range getGoroutines(fn) {
+		if !known.hasRecover(g.Call.StaticCallee()) {
+			pass.Reportf(g.Pos(), reportMsgGoroutine, fn.Name())
+		}
+	}
+}
+
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, targets *passTargets, srcFunc *ssa.Function) bool {
+	if srcFunc == nil {
+		panic("srcFunc is nil")
+	}
+	fileName := pass.Fset.Position(srcFunc.Pos()).Filename
+	if !targets.files.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+		return false
+	}
+	return true
+}
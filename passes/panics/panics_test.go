@@ -0,0 +1,25 @@
+package panics_test
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
+	"github.com/cloverrose/rpcguard/passes/panics"
+)
+
+func Test(t *testing.T) {
+	t.Parallel()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &panics.LogConfig.Level, "INFO")
+	pkgs := "a/a01core,a/a02helper,a/a02helper/helper"
+	configtest.Set(t, &panics.IncludePackages, "^(a/a01core|a/a02helper|a/a02helper/helper)$")
+	configtest.Set(t, &panics.RecoverFuncs, "a/external:Recover")
+	analysistest.Run(t, testdata, panics.Analyzer, strings.Split(pkgs, ",")...)
+}
@@ -0,0 +1,9 @@
+package a01core
+
+type App struct {
+	items []string
+}
+
+type Message struct {
+	text string
+}
@@ -0,0 +1,162 @@
+package a01core
+
+// This file contains RPC methods.
+// Note: The rpc_panic specifically reports only on RPC methods.
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"regexp"
+
+	"connectrpc.com/connect"
+
+	"a/external"
+)
+
+// NoPanic does not panic.
+func (app *App) NoPanic(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	return connect.NewResponse(&Message{"NoPanic"}), nil
+}
+
+// Panic calls panic directly.
+func (app *App) Panic(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // want Panic:"mayPanic\\(\\(\\*a/a01core.App\\).Panic -> panic\\)" "RPC method Panic may panic: \\(\\*a/a01core.App\\).Panic -> panic"
+	if req.Msg.text == "" {
+		panic("empty")
+	}
+	return connect.NewResponse(&Message{"Panic"}), nil
+}
+
+// LogFatal calls log.Fatal.
+func (app *App) LogFatal(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // want LogFatal:"mayPanic.*" "RPC method LogFatal may panic: \\(\\*a/a01core.App\\).LogFatal -> log.Fatal"
+	if req.Msg.text == "" {
+		log.Fatal("empty")
+	}
+	return connect.NewResponse(&Message{"LogFatal"}), nil
+}
+
+// LoggerFatalf calls (*log.Logger).Fatalf.
+func (app *App) LoggerFatalf(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // want LoggerFatalf:"mayPanic.*" "RPC method LoggerFatalf may panic: \\(\\*a/a01core.App\\).LoggerFatalf -> \\(\\*log.Logger\\).Fatalf"
+	if req.Msg.text == "" {
+		log.Default().Fatalf("empty %s", req.Msg.text)
+	}
+	return connect.NewResponse(&Message{"LoggerFatalf"}), nil
+}
+
+// OsExit calls os.Exit in helper.
+func (app *App) OsExit(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want OsExit:"mayPanic.*" "RPC method OsExit may panic: \\(\\*a/a01core.App\\).OsExit -> \\(\\*a/a01core.App\\).exit -> os.Exit"
+	app.exit()
+	return connect.NewResponse(&Message{"OsExit"}), nil
+}
+
+func (app *App) exit() { // want exit:"mayPanic.*"
+	os.Exit(1)
+}
+
+// MustCompile calls regexp.MustCompile.
+func (app *App) MustCompile(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) { // want MustCompile:"mayPanic.*" "RPC method MustCompile may panic: \\(\\*a/a01core.App\\).MustCompile -> regexp.MustCompile"
+	ptn := regexp.MustCompile(req.Msg.text)
+	return connect.NewResponse(&Message{ptn.String()}), nil
+}
+
+// CallOwnMust calls Must helper defined in the included package.
+func (app *App) CallOwnMust(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallOwnMust:"mayPanic.*" "RPC method CallOwnMust may panic: \\(\\*a/a01core.App\\).CallOwnMust -> a/a01core.Must -> panic"
+	text := Must(app.first())
+	return connect.NewResponse(&Message{text}), nil
+}
+
+func Must(text string, err error) string { // want Must:"mayPanic.*"
+	if err != nil {
+		panic(err)
+	}
+	return text
+}
+
+func (app *App) first() (string, error) {
+	if len(app.items) == 0 {
+		return "", errors.New("empty")
+	}
+	return app.items[0], nil
+}
+
+// RecoverPanic calls panic but recovers it.
+func (app *App) RecoverPanic(_ context.Context, req *connect.Request[Message]) (res *connect.Response[Message], err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = connect.NewError(connect.CodeInternal, errors.New("panic"))
+		}
+	}()
+	app.exit()
+	return connect.NewResponse(&Message{"RecoverPanic"}), nil
+}
+
+// CallRecoveredHelper calls helper that recovers panic.
+func (app *App) CallRecoveredHelper(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	app.safe()
+	return connect.NewResponse(&Message{"CallRecoveredHelper"}), nil
+}
+
+func (app *App) safe() {
+	defer app.recover()
+	panic("recovered")
+}
+
+func (app *App) recover() {
+	_ = recover()
+}
+
+// CallRecursivePanic calls recursive helpers one of them panics.
+func (app *App) CallRecursivePanic(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallRecursivePanic:"mayPanic.*" "RPC method CallRecursivePanic may panic: \\(\\*a/a01core.App\\).CallRecursivePanic -> .* -> panic"
+	app.ping(3)
+	return connect.NewResponse(&Message{"CallRecursivePanic"}), nil
+}
+
+func (app *App) ping(n int) { // want ping:"mayPanic.*"
+	if n > 0 {
+		app.pong(n - 1)
+	}
+}
+
+func (app *App) pong(n int) { // want pong:"mayPanic.*"
+	if n == 1 {
+		panic("pong")
+	}
+	app.ping(n - 1)
+}
+
+// StartGoroutine starts goroutine without recover.
+func (app *App) StartGoroutine(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	go func() { // want "RPC method StartGoroutine starts goroutine without recover"
+		_, _ = app.first()
+	}()
+	return connect.NewResponse(&Message{"StartGoroutine"}), nil
+}
+
+// StartGoroutineWithRecover starts goroutine with recover.
+func (app *App) StartGoroutineWithRecover(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	go func() {
+		defer func() {
+			_ = recover()
+		}()
+		app.exit()
+	}()
+	return connect.NewResponse(&Message{"StartGoroutineWithRecover"}), nil
+}
+
+// RecoverByExternalHelper calls panic but recovers it by helper in RecoverFuncs.
+func (app *App) RecoverByExternalHelper(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	defer external.Recover()
+	panic("recovered")
+}
+
+// DeferRecoverNamedHelper calls panic and defers helper which is not in RecoverFuncs.
+func (app *App) DeferRecoverNamedHelper(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want DeferRecoverNamedHelper:"mayPanic.*" "RPC method DeferRecoverNamedHelper may panic: \\(\\*a/a01core.App\\).DeferRecoverNamedHelper -> panic"
+	defer external.RecordRecoverCount()
+	panic("not recovered")
+}
+
+// CallExternalMust calls Must helper which is not in MustFuncs.
+func (app *App) CallExternalMust(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	return connect.NewResponse(&Message{external.MustParse(req.Msg.text)}), nil
+}
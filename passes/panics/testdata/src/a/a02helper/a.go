@@ -0,0 +1,27 @@
+package a02helper
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+
+	"a/a02helper/helper"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// CallHelperInOtherPackage calls helper that panics defined in other included package.
+func (app *App) CallHelperInOtherPackage(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallHelperInOtherPackage:"mayPanic.*" "RPC method CallHelperInOtherPackage may panic: \\(\\*a/a02helper.App\\).CallHelperInOtherPackage -> a/a02helper/helper.MustGet -> panic"
+	text := helper.MustGet([]string{"hello"})
+	return connect.NewResponse(&Message{text}), nil
+}
+
+// CallSafeHelper calls helper that doesn't panic defined in other included package.
+func (app *App) CallSafeHelper(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	text := helper.Get([]string{"hello"})
+	return connect.NewResponse(&Message{text}), nil
+}
@@ -0,0 +1,12 @@
+package helper
+
+func Get(items []string) string {
+	return items[0]
+}
+
+func MustGet(items []string) string { // want MustGet:"mayPanic\\(a/a02helper/helper.MustGet -> panic\\)"
+	if len(items) == 0 {
+		panic("empty")
+	}
+	return items[0]
+}
@@ -0,0 +1,15 @@
+// Package external is not included, its functions are judged by RecoverFuncs and MustFuncs.
+package external
+
+// Recover recovers panic, it is listed in RecoverFuncs.
+func Recover() {
+	_ = recover()
+}
+
+// RecordRecoverCount doesn't recover panic even though its name contains "recover".
+func RecordRecoverCount() {}
+
+// MustParse panics on error, but it is not listed in MustFuncs.
+func MustParse(text string) string {
+	return text
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/cloverrose/rpcguard/pkg/graph"
 
 	"github.com/cloverrose/rpcguard/passes/wraperr/rtn"
-	"github.com/cloverrose/rpcguard/passes/wraperr/visitors/norm"
 )
 
 type CallGraph struct {
@@ -44,11 +43,11 @@ func (cg *CallGraph) Scan(srcFunc *ssa.Function) error {
 	return nil
 }
 
-// scanFunc scans single function and returns all returnInfo.
+// scanFunc scans single function and returns all Source of its returns.
 func scanFunc(fn *ssa.Function, indices []int) (*FuncInfo, error) {
-	data := make(map[*ssa.Return]*returnInfo)
+	data := make(map[*ssa.Return]*Source)
 	for _, val := range rtn.GetReturnsAt(fn, indices) {
-		info, err := scanVal(val.Value, getTargetIndex(val.Value))
+		info, err := scanVal(fn, val.Value, getTargetIndex(val.Value))
 		if err != nil {
 			return nil, err
 		}
@@ -66,27 +65,35 @@ func getTargetIndex(val ssa.Value) []int {
 	}
 }
 
-// scanVal scans single return value and returns returnInfo.
-func scanVal(val ssa.Value, indices []int) (*returnInfo, error) {
-	plugin := &visitorPlugin{
-		normalizeFunc: norm.NewNormalizeFunc(indices),
-	}
+// scanVal scans single return value of owner and returns its Source.
+func scanVal(owner *ssa.Function, val ssa.Value, indices []int) (*Source, error) {
+	plugin := newVisitorPlugin(owner, indices, make(map[*ssa.Call]struct{}))
 	if err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(plugin.createOptions()...), val); err != nil {
 		return nil, err
 	}
-	return &returnInfo{
-		toFuncs:        plugin.toFuncs,
-		isObviouslyBad: plugin.isBad,
-	}, nil
+	return plugin.source(), nil
 }
 
 type scanPluginFunc func(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error)
 
-// ScanWithPlugin scans fn with plugin and updates call graph.
-func (cg *CallGraph) ScanWithPlugin(plugin scanPluginFunc, fn *ssa.Function) error {
+// MarkUnsupported records srcFunc as a function that can't be analyzed.
+// It is treated as obviously bad, and further scans are skipped.
+func (cg *CallGraph) MarkUnsupported(srcFunc *ssa.Function) {
+	if _, ok := cg.data[srcFunc]; !ok {
+		cg.order = append(cg.order, srcFunc)
+	}
+	cg.data[srcFunc] = &FuncInfo{data: make(map[*ssa.Return]*Source), unsupported: true}
+}
+
+// ScanWithPlugin scans fn with plugin of the propagation model and updates call graph.
+// Replaced funcs are recorded as Substitutions of the Source.
+func (cg *CallGraph) ScanWithPlugin(model string, plugin scanPluginFunc, fn *ssa.Function) error {
 	if _, ok := cg.data[fn]; !ok {
 		return fmt.Errorf("unexpected: not found for fn: %s", fn)
 	}
+	if cg.data[fn].unsupported {
+		return nil
+	}
 
 	rtnToReplacements, err := plugin(fn, cg.indicesFunc)
 	if err != nil {
@@ -100,20 +107,27 @@ func (cg *CallGraph) ScanWithPlugin(plugin scanPluginFunc, fn *ssa.Function) err
 		if len(replacement) == 0 {
 			continue
 		}
-		newToFuncs := make([]*ssa.Function, 0, len(cg.data[fn].data[rt].toFuncs))
-		for _, toFunc := range cg.data[fn].data[rt].toFuncs {
+		src := cg.data[fn].data[rt]
+		newToFuncs := make([]*ssa.Function, 0, len(src.ToFuncs))
+		for _, toFunc := range src.ToFuncs {
 			converts, ok := replacement[toFunc]
 			if ok {
 				newToFuncs = append(newToFuncs, converts...)
+				src.Substitutions = append(src.Substitutions, &Substitution{Model: model, From: toFunc, To: converts})
 			} else {
 				newToFuncs = append(newToFuncs, toFunc)
 			}
 		}
-		cg.data[fn].data[rt].toFuncs = newToFuncs
+		src.ToFuncs = newToFuncs
 	}
 	return nil
 }
 
+// Funcs returns scanned functions in scan order.
+func (cg *CallGraph) Funcs() []*ssa.Function {
+	return cg.order
+}
+
 // GetReturnInfo returns FuncInfo for the given f.
 func (cg *CallGraph) GetReturnInfo(f *ssa.Function) *FuncInfo {
 	info, ok := cg.data[f]
@@ -130,6 +144,12 @@ func (cg *CallGraph) Convert() *graph.Graph[*ssa.Function] {
 		for _, toFunc := range info.GetAllToFuncs() {
 			g.AddEdge(srcFunc, toFunc)
 		}
+		// functions passed to callees should be checked before srcFunc.
+		for _, src := range info.data {
+			for _, toFunc := range src.ArgToFuncs() {
+				g.AddEdge(srcFunc, toFunc)
+			}
+		}
 	}
 	return g
 }
@@ -151,16 +171,53 @@ func (cg *CallGraph) LogValue() slog.Value {
 	return slog.StringValue(string(jsonBytes))
 }
 
-// returnInfo holds information for single return value.
-type returnInfo struct {
-	toFuncs        []*ssa.Function // source of this return value's functions.
-	isObviouslyBad bool            // if this return is obviously bad or not. E.g. return returns non function (alloc etc).
+// Source holds information for single value, e.g. return value or function-typed argument.
+type Source struct {
+	ToFuncs        []*ssa.Function // source of this value's functions.
+	IsObviouslyBad bool            // if this value is obviously bad or not. E.g. value is non function (alloc etc).
+	Params         []int           // indices of function-typed parameters of owner function that are source of this value.
+	ParentParams   []int           // indices of function-typed parameters of enclosing function captured by owner closure.
+	CallSites      []*CallSite     // static calls whose results are source of this value.
+	Substitutions  []*Substitution // replacements of ToFuncs by propagation models.
+}
+
+// Substitution records that a propagation model replaced From with To in ToFuncs.
+// E.g. errgroup model replaces (*errgroup.Group).Wait with funcs passed to (*errgroup.Group).Go.
+type Substitution struct {
+	Model string
+	From  *ssa.Function
+	To    []*ssa.Function
+}
+
+// ArgToFuncs returns all functions that are passed to callees of this value.
+func (s *Source) ArgToFuncs() []*ssa.Function {
+	var ret []*ssa.Function
+	for _, callSite := range s.CallSites {
+		for _, arg := range callSite.Args {
+			ret = append(ret, arg.ToFuncs...)
+			ret = append(ret, arg.ArgToFuncs()...)
+		}
+	}
+	return ret
+}
+
+// CallSite holds a static call and its function-typed arguments.
+// When Callee returns the result of its parameter i, the caller's value is judged by Args[i].
+type CallSite struct {
+	Callee *ssa.Function
+	Args   map[int]*Source // key is index of Callee.Params.
 }
 
 // FuncInfo holds information for single function.
-// Single function has several returns, data holds mapping from return to returnInfo.
+// Single function has several returns, data holds mapping from return to Source.
 type FuncInfo struct {
-	data map[*ssa.Return]*returnInfo
+	data        map[*ssa.Return]*Source
+	unsupported bool // true if function can't be analyzed, data is empty.
+}
+
+// IsUnsupported returns true if this function can't be analyzed.
+func (i *FuncInfo) IsUnsupported() bool {
+	return i.unsupported
 }
 
 // GetReturns returns all returns of single function.
@@ -172,15 +229,18 @@ func (i *FuncInfo) GetReturns() []*ssa.Return {
 func (i *FuncInfo) GetAllToFuncs() []*ssa.Function {
 	ret := make([]*ssa.Function, 0, len(i.data))
 	for _, info := range i.data {
-		ret = append(ret, info.toFuncs...)
+		ret = append(ret, info.ToFuncs...)
 	}
 	return ret
 }
 
 // IsObviouslyBad returns true if this function is obviously bad.
 func (i *FuncInfo) IsObviouslyBad() bool {
+	if i.unsupported {
+		return true
+	}
 	for _, info := range i.data {
-		if info.isObviouslyBad {
+		if info.IsObviouslyBad {
 			return true
 		}
 	}
@@ -198,7 +258,7 @@ func (i *FuncInfo) IsObviouslyBadReturn(rtn *ssa.Return) bool {
 	if !ok {
 		return false
 	}
-	return info.isObviouslyBad
+	return info.IsObviouslyBad
 }
 
 // GetToFuncs returns toFuncs for the given rtn.
@@ -207,5 +267,35 @@ func (i *FuncInfo) GetToFuncs(rtn *ssa.Return) []*ssa.Function {
 	if !ok {
 		return nil
 	}
-	return info.toFuncs
+	return info.ToFuncs
+}
+
+// GetSource returns Source for the given rtn.
+func (i *FuncInfo) GetSource(rtn *ssa.Return) *Source {
+	info, ok := i.data[rtn]
+	if !ok {
+		return nil
+	}
+	return info
+}
+
+// GetParams returns indices of function-typed parameters that are directly returned (or called and returned).
+func (i *FuncInfo) GetParams() []int {
+	var ret []int
+	for _, info := range i.data {
+		ret = append(ret, info.Params...)
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// GetParentParams returns indices of function-typed parameters of enclosing function
+// that are captured and directly returned (or called and returned).
+func (i *FuncInfo) GetParentParams() []int {
+	var ret []int
+	for _, info := range i.data {
+		ret = append(ret, info.ParentParams...)
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret)
 }
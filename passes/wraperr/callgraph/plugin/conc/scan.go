@@ -0,0 +1,40 @@
+package conc
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains github.com/sourcegraph/conc/pool support.
+// pool.Wait() returns errors returned by funcs passed to pool.Go(f) like errgroup.
+// ErrorPool and ContextPool are supported.
+
+/**
+func Foo(ctx context.Context) error {
+	p := pool.New().WithErrors()
+	p.Go(func() error { // <- in ssa, this anonymous func name is Foo$1
+		return okFunc1(ctx)
+	})
+	return p.Wait() // <- replaced with Foo$1
+}
+**/
+
+const poolPath = "github.com/sourcegraph/conc/pool"
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanGroup(fn, indicesFunc, pluginutil.GroupModel{
+		IsWait: isWait,
+		IsGo:   isGo,
+	})
+}
+
+// isGo returns true if fn is (*pool.ErrorPool).Go or (*pool.ContextPool).Go.
+func isGo(fn *ssa.Function) bool {
+	return pluginutil.IsFunc(fn, poolPath, "ErrorPool", "Go") || pluginutil.IsFunc(fn, poolPath, "ContextPool", "Go")
+}
+
+// isWait returns true if fn is (*pool.ErrorPool).Wait or (*pool.ContextPool).Wait.
+func isWait(fn *ssa.Function) bool {
+	return pluginutil.IsFunc(fn, poolPath, "ErrorPool", "Wait") || pluginutil.IsFunc(fn, poolPath, "ContextPool", "Wait")
+}
@@ -1,13 +1,9 @@
 package eg
 
 import (
-	"fmt"
-
 	"golang.org/x/tools/go/ssa"
 
-	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
-
-	"github.com/cloverrose/rpcguard/passes/wraperr/rtn"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
 )
 
 // This file contains errgroup support.
@@ -67,20 +63,8 @@ func badFunc1(ctx context.Context, x int) error {
 **/
 
 func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
-	indices := indicesFunc(fn)
-	if len(indices) == 0 {
-		// srcFunc does not return error-ish values.
-		return nil, fmt.Errorf("no indices for fn: %s", fn)
-	}
-
-	rtnToReplacements := make(map[*ssa.Return]map[*ssa.Function][]*ssa.Function)
-	for _, val := range rtn.GetReturnsAt(fn, indices) {
-		plugin := newVisitorPlugin()
-		visitor := ssawalk.NewDefaultVisitorWith(plugin.createOptions()...)
-		if err := ssawalk.Walk(visitor, val.Value); err != nil {
-			return nil, err
-		}
-		rtnToReplacements[val.Return] = plugin.replacements
-	}
-	return rtnToReplacements, nil
+	return pluginutil.ScanGroup(fn, indicesFunc, pluginutil.GroupModel{
+		IsWait: isWait,
+		IsGo:   isGo,
+	})
 }
@@ -0,0 +1,38 @@
+package errorsjoin
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains errors.Join support.
+// errors.Join(a, b) returns error that wraps a and b.
+// Since errors.As finds the first connect.Error in the tree, errors.Join(a, b) is ok only if both a and b are ok.
+
+/**
+func Foo(ctx context.Context) error {
+	err1 := okFunc1(ctx)
+	err2 := okFunc2(ctx)
+	return errors.Join(err1, err2) // <- replaced with okFunc1 and okFunc2
+}
+**/
+
+const errorsPath = "errors"
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanCombine(fn, indicesFunc, pluginutil.CombineModel{
+		Parts: parts,
+	})
+}
+
+// parts returns errors combined by errors.Join(errs...).
+func parts(call *ssa.Call) ([]ssa.Value, bool) {
+	if !pluginutil.IsFunc(call.Call.StaticCallee(), errorsPath, "", "Join") {
+		return nil, false
+	}
+	if len(call.Call.Args) != 1 {
+		return nil, false
+	}
+	return pluginutil.VariadicArgs(call.Call.Args[0]), true
+}
@@ -0,0 +1,83 @@
+package fmterrorf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// wrappedArgIndices returns indices of args that are bound to %w in format.
+// It follows the rules of fmt package: flags, width and precision (including *) and explicit argument indexes like %[2]w.
+func wrappedArgIndices(format string) []int {
+	var indices []int
+	argNum := 0
+	i := 0
+	for i < len(format) {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		i++
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '%' {
+			i++
+			continue
+		}
+		// flags
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		// width
+		i, argNum = parseArgIndex(format, i, argNum)
+		if i < len(format) && format[i] == '*' {
+			i++
+			argNum++
+		}
+		for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+			i++
+		}
+		// precision
+		if i < len(format) && format[i] == '.' {
+			i++
+			i, argNum = parseArgIndex(format, i, argNum)
+			if i < len(format) && format[i] == '*' {
+				i++
+				argNum++
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				i++
+			}
+		}
+		// verb
+		i, argNum = parseArgIndex(format, i, argNum)
+		if i >= len(format) {
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == 'w' {
+			indices = append(indices, argNum)
+		}
+		argNum++
+	}
+	return indices
+}
+
+// parseArgIndex parses explicit argument index like [2] at i.
+// It returns next position and argNum (0-origin) to be used.
+func parseArgIndex(format string, i, argNum int) (int, int) {
+	if i >= len(format) || format[i] != '[' {
+		return i, argNum
+	}
+	end := strings.IndexByte(format[i:], ']')
+	if end < 0 {
+		return i, argNum
+	}
+	n, err := strconv.Atoi(format[i+1 : i+end])
+	if err != nil || n < 1 {
+		return i + end + 1, argNum
+	}
+	return i + end + 1, n - 1
+}
@@ -0,0 +1,37 @@
+package fmterrorf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrappedArgIndices(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format string
+		want   []int
+	}{
+		{format: "no verb", want: nil},
+		{format: "%v", want: nil},
+		{format: "%w", want: []int{0}},
+		{format: "%d %w", want: []int{1}},
+		{format: "100%% %w", want: []int{0}},
+		{format: "%w, %w", want: []int{0, 1}},
+		{format: "%+v %-10s %w", want: []int{2}},
+		{format: "%*d %w", want: []int{2}},
+		{format: "%.*f %w", want: []int{2}},
+		{format: "%[2]w: %[1]v", want: []int{1}},
+		{format: "%[2]v %w", want: []int{2}},
+		{format: "%", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+			got := wrappedArgIndices(tt.format)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("wrappedArgIndices(%q) mismatch (-want +got):\n%s", tt.format, diff)
+			}
+		})
+	}
+}
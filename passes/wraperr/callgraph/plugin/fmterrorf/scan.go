@@ -0,0 +1,85 @@
+package fmterrorf
+
+import (
+	"go/constant"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains fmt.Errorf support.
+// fmt.Errorf("...: %w", err) returns error that wraps err.
+// Since connect.CodeOf uses errors.As, the returned error still has err's code.
+// Thus, fmt.Errorf is ok only if all operands of %w are ok.
+// fmt.Errorf without %w (E.g. fmt.Errorf("%v", err)) doesn't wrap err, so it is bad.
+// fmt.Errorf("x: %w", nil) returns non nil error without code, so nil operand is bad.
+
+/**
+func Foo(ctx context.Context) error {
+	if err := okFunc1(ctx); err != nil {
+		return fmt.Errorf("okFunc1: %w", err) // <- replaced with okFunc1
+	}
+	return nil
+}
+**/
+
+const fmtPath = "fmt"
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanCombine(fn, indicesFunc, pluginutil.CombineModel{
+		Parts:    parts,
+		NilIsBad: true,
+	})
+}
+
+// parts returns operands of %w in fmt.Errorf(format, args...).
+// If the operands can't be determined, it returns format itself, it is not error and treated as bad.
+func parts(call *ssa.Call) ([]ssa.Value, bool) {
+	if !pluginutil.IsFunc(call.Call.StaticCallee(), fmtPath, "", "Errorf") {
+		return nil, false
+	}
+	if len(call.Call.Args) != 2 {
+		return nil, false
+	}
+	formatArg := call.Call.Args[0]
+	format, ok := formatArg.(*ssa.Const)
+	if !ok || format.Value == nil || format.Value.Kind() != constant.String {
+		return []ssa.Value{formatArg}, true
+	}
+	indices := wrappedArgIndices(constant.StringVal(format.Value))
+	if len(indices) == 0 {
+		// no %w
+		return []ssa.Value{formatArg}, true
+	}
+
+	args := pluginutil.VariadicArgs(call.Call.Args[1])
+	if len(args) == 1 && args[0] == call.Call.Args[1] {
+		// args can't be determined. E.g. fmt.Errorf(format, args...)
+		return args, true
+	}
+	ret := make([]ssa.Value, 0, len(indices))
+	for _, index := range indices {
+		if index >= len(args) {
+			// %!w(MISSING)
+			return []ssa.Value{formatArg}, true
+		}
+		ret = append(ret, unwrapInterface(args[index]))
+	}
+	return ret, true
+}
+
+// unwrapInterface returns the value before conversion to any.
+// Args of fmt.Errorf are []any, so err is converted to any.
+//
+//nolint:ireturn // interface ssa.Value is ok to return.
+func unwrapInterface(val ssa.Value) ssa.Value {
+	switch val := val.(type) {
+	case *ssa.MakeInterface:
+		return val.X
+	case *ssa.ChangeInterface:
+		return val.X
+	default:
+		return val
+	}
+}
@@ -0,0 +1,46 @@
+package multierr
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains go.uber.org/multierr support.
+// multierr.Append(a, b) and multierr.Combine(a, b) return error that wraps a and b.
+// Thus, they are ok only if both a and b are ok.
+
+/**
+func Foo(ctx context.Context) (err error) {
+	err = multierr.Append(err, okFunc1(ctx))
+	err = multierr.Append(err, okFunc2(ctx))
+	return err // <- replaced with okFunc1 and okFunc2
+}
+**/
+
+const multierrPath = "go.uber.org/multierr"
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanCombine(fn, indicesFunc, pluginutil.CombineModel{
+		Parts: parts,
+	})
+}
+
+// parts returns errors combined by multierr.Append(left, right) or multierr.Combine(errs...).
+func parts(call *ssa.Call) ([]ssa.Value, bool) {
+	callee := call.Call.StaticCallee()
+	switch {
+	case pluginutil.IsFunc(callee, multierrPath, "", "Append"):
+		if len(call.Call.Args) != 2 {
+			return nil, false
+		}
+		return call.Call.Args, true
+	case pluginutil.IsFunc(callee, multierrPath, "", "Combine"):
+		if len(call.Call.Args) != 1 {
+			return nil, false
+		}
+		return pluginutil.VariadicArgs(call.Call.Args[0]), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,52 @@
+package multierror
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains github.com/hashicorp/go-multierror support.
+// multierror.Append(err, errs...) returns *multierror.Error that wraps err and errs,
+// and (*multierror.Error).ErrorOrNil() returns the receiver itself or nil.
+// Thus, they are ok only if all wrapped errors are ok.
+
+/**
+func Foo(ctx context.Context) error {
+	var result *multierror.Error
+	if err := okFunc1(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+	if err := okFunc2(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+	return result.ErrorOrNil() // <- replaced with okFunc1 and okFunc2
+}
+**/
+
+const multierrorPath = "github.com/hashicorp/go-multierror"
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanCombine(fn, indicesFunc, pluginutil.CombineModel{
+		Parts: parts,
+	})
+}
+
+// parts returns errors combined by multierror.Append(err, errs...) or result.ErrorOrNil().
+func parts(call *ssa.Call) ([]ssa.Value, bool) {
+	callee := call.Call.StaticCallee()
+	switch {
+	case pluginutil.IsFunc(callee, multierrorPath, "", "Append"):
+		if len(call.Call.Args) != 2 {
+			return nil, false
+		}
+		return append([]ssa.Value{call.Call.Args[0]}, pluginutil.VariadicArgs(call.Call.Args[1])...), true
+	case pluginutil.IsFunc(callee, multierrorPath, "Error", "ErrorOrNil"):
+		if len(call.Call.Args) != 1 {
+			return nil, false
+		}
+		return call.Call.Args, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,45 @@
+package pkgerrors
+
+import (
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pluginutil"
+)
+
+// This file contains github.com/pkg/errors support.
+// errors.WithStack(err), errors.Wrap(err, msg) etc. return error that wraps err (and return nil if err is nil).
+// Since connect.CodeOf uses errors.As, the returned error still has err's code.
+// Thus, they are ok only if err is ok.
+// Unlike fmt.Errorf("%w", nil), they return nil for nil err, so nil err is ok.
+
+/**
+func Foo(ctx context.Context) error {
+	if err := okFunc1(ctx); err != nil {
+		return errors.WithStack(err) // <- replaced with okFunc1
+	}
+	return nil
+}
+**/
+
+const pkgErrorsPath = "github.com/pkg/errors"
+
+// wrapperNames are funcs that take err as the first argument and wrap it.
+var wrapperNames = []string{"WithStack", "Wrap", "Wrapf", "WithMessage", "WithMessagef"}
+
+func Scan(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	return pluginutil.ScanCombine(fn, indicesFunc, pluginutil.CombineModel{
+		Parts:    parts,
+		NilIsBad: false,
+	})
+}
+
+// parts returns err wrapped by errors.WithStack(err) etc.
+func parts(call *ssa.Call) ([]ssa.Value, bool) {
+	if !pluginutil.IsFunc(call.Call.StaticCallee(), pkgErrorsPath, "", wrapperNames...) {
+		return nil, false
+	}
+	if len(call.Call.Args) == 0 {
+		return nil, false
+	}
+	return call.Call.Args[:1], true
+}
@@ -0,0 +1,247 @@
+package pluginutil
+
+import (
+	"go/token"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+)
+
+// This file tracks identity of task groups (errgroup.Group, conc pool etc.) across values and functions.
+// eg.Wait() receiver and eg.Go() receiver are not always the same ssa.Value.
+//
+//	var g errgroup.Group         // Alloc, loaded by UnOp
+//	s.g.Go(f)                    // FieldAddr, may be accessed in other methods
+//	addTasks(&g)                 // Parameter of addTasks
+//	g := newGroup(ctx)           // Return value of newGroup
+//	func() { g.Go(f) }()         // FreeVar of closure
+//
+// Aliases collects values that may refer to the same group.
+// Struct fields are identified by (struct type, field index), so different instances of the same struct share aliases.
+
+// alias is an item of worklist.
+type alias struct {
+	value ssa.Value
+
+	// inner is true if value was reached by entering a callee (argument to parameter, binding to free variable, return value).
+	// Parameters and free variables of such callee are not traced back to other callers,
+	// otherwise a helper called from multiple functions would mix groups of those callers.
+	inner bool
+}
+
+type aliasTracker struct {
+	seen    map[ssa.Value]struct{}
+	queue   []alias
+	aliases []ssa.Value
+
+	// funcs is all functions in the package. It is lazily built since most groups are local.
+	pkg   *ssa.Package
+	funcs []*ssa.Function
+}
+
+// Aliases returns values that may refer to the same group as receiver.
+func Aliases(receiver ssa.Value) []ssa.Value {
+	t := &aliasTracker{
+		seen: make(map[ssa.Value]struct{}),
+	}
+	if fn := receiver.Parent(); fn != nil {
+		t.pkg = fn.Pkg
+	}
+	t.push(receiver, false)
+	for len(t.queue) > 0 {
+		item := t.queue[0]
+		t.queue = t.queue[1:]
+		t.visitDefinition(item)
+		t.visitReferrers(item)
+	}
+	return t.aliases
+}
+
+func (t *aliasTracker) push(value ssa.Value, inner bool) {
+	if value == nil {
+		return
+	}
+	if _, ok := t.seen[value]; ok {
+		return
+	}
+	t.seen[value] = struct{}{}
+	t.aliases = append(t.aliases, value)
+	t.queue = append(t.queue, alias{value: value, inner: inner})
+}
+
+// visitDefinition follows value to where it comes from.
+func (t *aliasTracker) visitDefinition(item alias) {
+	switch val := item.value.(type) {
+	case *ssa.UnOp:
+		if val.Op == token.MUL {
+			// g := *addr
+			t.push(val.X, item.inner)
+		}
+	case *ssa.FieldAddr:
+		// s.g
+		for _, peer := range t.fieldAddrs(val) {
+			t.push(peer, item.inner)
+		}
+	case *ssa.Phi:
+		for _, edge := range val.Edges {
+			t.push(edge, item.inner)
+		}
+	case *ssa.Parameter:
+		if item.inner {
+			return
+		}
+		// func helper(g *errgroup.Group) { g.Wait() }
+		for _, arg := range t.callerArgs(val) {
+			t.push(arg, false)
+		}
+	case *ssa.FreeVar:
+		if item.inner {
+			return
+		}
+		for _, binding := range t.closureBindings(val) {
+			t.push(binding, false)
+		}
+	case *ssa.Extract:
+		// g, ctx := newGroup(ctx)
+		if call, ok := val.Tuple.(*ssa.Call); ok {
+			t.pushResults(call, val.Index)
+		}
+	case *ssa.Call:
+		// g := newGroup()
+		t.pushResults(val, 0)
+	}
+}
+
+// visitReferrers follows value to where it goes.
+func (t *aliasTracker) visitReferrers(item alias) {
+	refs := item.value.Referrers()
+	if refs == nil {
+		return
+	}
+	for _, instr := range *refs {
+		switch instr := instr.(type) {
+		case *ssa.UnOp:
+			if instr.Op == token.MUL && instr.X == item.value {
+				t.push(instr, item.inner)
+			}
+		case *ssa.Store:
+			if instr.Val == item.value {
+				// addr = g
+				t.push(instr.Addr, item.inner)
+			} else {
+				// *addr = g
+				t.push(instr.Val, item.inner)
+			}
+		case *ssa.Phi:
+			t.push(instr, item.inner)
+		case *ssa.MakeClosure:
+			for i, binding := range instr.Bindings {
+				if binding != item.value {
+					continue
+				}
+				if closure, ok := instr.Fn.(*ssa.Function); ok && i < len(closure.FreeVars) {
+					t.push(closure.FreeVars[i], true)
+				}
+			}
+		case *ssa.Call:
+			// addTasks(g)
+			callee := instr.Call.StaticCallee()
+			if callee == nil || len(callee.Blocks) == 0 {
+				continue
+			}
+			for i, arg := range instr.Call.Args {
+				if arg == item.value && i < len(callee.Params) {
+					t.push(callee.Params[i], true)
+				}
+			}
+		}
+	}
+}
+
+// pushResults pushes values returned by call at index.
+func (t *aliasTracker) pushResults(call *ssa.Call, index int) {
+	callee := call.Call.StaticCallee()
+	if callee == nil {
+		return
+	}
+	for _, block := range callee.Blocks {
+		for _, instr := range block.Instrs {
+			ret, ok := instr.(*ssa.Return)
+			if !ok || index >= len(ret.Results) {
+				continue
+			}
+			t.push(ret.Results[index], true)
+		}
+	}
+}
+
+// fieldAddrs returns all FieldAddr in the package that point to the same field with addr.
+func (t *aliasTracker) fieldAddrs(addr *ssa.FieldAddr) []ssa.Value {
+	var ret []ssa.Value
+	for _, fn := range t.packageFuncs() {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				peer, ok := instr.(*ssa.FieldAddr)
+				if !ok || peer.Field != addr.Field || !types.Identical(peer.X.Type(), addr.X.Type()) {
+					continue
+				}
+				ret = append(ret, peer)
+			}
+		}
+	}
+	return ret
+}
+
+// callerArgs returns arguments passed to param by static calls in the package.
+func (t *aliasTracker) callerArgs(param *ssa.Parameter) []ssa.Value {
+	fn := param.Parent()
+	index := slices.Index(fn.Params, param)
+	if index < 0 {
+		return nil
+	}
+	var ret []ssa.Value
+	for _, caller := range t.packageFuncs() {
+		for _, block := range caller.Blocks {
+			for _, instr := range block.Instrs {
+				call, ok := instr.(ssa.CallInstruction)
+				if !ok || call.Common().StaticCallee() != fn || index >= len(call.Common().Args) {
+					continue
+				}
+				ret = append(ret, call.Common().Args[index])
+			}
+		}
+	}
+	return ret
+}
+
+// closureBindings returns values bound to freeVar by MakeClosure in the enclosing function.
+func (t *aliasTracker) closureBindings(freeVar *ssa.FreeVar) []ssa.Value {
+	fn := freeVar.Parent()
+	index := slices.Index(fn.FreeVars, freeVar)
+	if index < 0 || fn.Parent() == nil {
+		return nil
+	}
+	var ret []ssa.Value
+	for _, block := range fn.Parent().Blocks {
+		for _, instr := range block.Instrs {
+			mc, ok := instr.(*ssa.MakeClosure)
+			if !ok || mc.Fn != fn || index >= len(mc.Bindings) {
+				continue
+			}
+			ret = append(ret, mc.Bindings[index])
+		}
+	}
+	return ret
+}
+
+// packageFuncs returns functions, methods and their closures defined in the package.
+func (t *aliasTracker) packageFuncs() []*ssa.Function {
+	if t.funcs != nil || t.pkg == nil {
+		return t.funcs
+	}
+	t.funcs = ssawalk.PackageFuncs(t.pkg)
+	return t.funcs
+}
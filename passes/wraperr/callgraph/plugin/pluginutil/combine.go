@@ -0,0 +1,199 @@
+package pluginutil
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/gostaticanalysis/analysisutil"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/rtn"
+	"github.com/cloverrose/rpcguard/passes/wraperr/visitors/norm"
+)
+
+// This file contains support for functions that combine multiple errors into one error like errors.Join.
+// ssa connects the caller to errors.Join, and errors.Join is defined in other package, so it is treated as bad func.
+// ScanCombine replaces errors.Join with funcs that return the combined errors.
+// Thus, errors.Join(a, b) is ok only if both a and b are ok.
+// If some parts can't be analyzed (E.g. errors.Join(errs...)), errors.Join itself is kept and treated as bad func.
+
+// CombineModel describes functions that combine errors.
+type CombineModel struct {
+	// Parts returns error values combined by call.
+	// ok is false if call is not a call of combining function.
+	Parts func(call *ssa.Call) (parts []ssa.Value, ok bool)
+	// NilIsBad is true if the function returns non nil error for nil parts.
+	// E.g. fmt.Errorf("x: %w", nil) returns *fmt.wrapError which has no connect code,
+	// while errors.Join(nil) returns nil.
+	NilIsBad bool
+}
+
+// ScanCombine scans fn and returns replacements from combining functions to funcs that return combined errors for each return.
+func ScanCombine(
+	fn *ssa.Function,
+	indicesFunc func(fn *ssa.Function) []int,
+	model CombineModel,
+) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	indices := indicesFunc(fn)
+	if len(indices) == 0 {
+		// srcFunc does not return error-ish values.
+		return nil, fmt.Errorf("no indices for fn: %s", fn)
+	}
+
+	rtnToReplacements := make(map[*ssa.Return]map[*ssa.Function][]*ssa.Function)
+	for _, val := range rtn.GetReturnsAt(fn, indices) {
+		plugin := &combineVisitorPlugin{
+			model:        model,
+			replacements: make(map[*ssa.Function][]*ssa.Function),
+		}
+		visitor := ssawalk.NewDefaultVisitorWith(ssawalk.WithVisitCall(plugin.VisitCall))
+		if err := ssawalk.Walk(visitor, val.Value); err != nil {
+			return nil, err
+		}
+		rtnToReplacements[val.Return] = plugin.replacements
+	}
+	return rtnToReplacements, nil
+}
+
+type combineVisitorPlugin struct {
+	model        CombineModel
+	replacements map[*ssa.Function][]*ssa.Function
+}
+
+func (p *combineVisitorPlugin) VisitCall(call *ssa.Call) error {
+	parts, ok := p.model.Parts(call)
+	if !ok {
+		return nil
+	}
+	combineFunc := call.Call.StaticCallee()
+	if combineFunc == nil {
+		return nil
+	}
+
+	scanner := &partsScanner{
+		model: p.model,
+		seen:  map[*ssa.Call]struct{}{call: {}},
+	}
+	if err := scanner.scanParts(parts); err != nil {
+		return err
+	}
+	toFuncs := scanner.toFuncs
+	if scanner.isBad {
+		// keep combineFunc, it is treated as bad func.
+		toFuncs = append(toFuncs, combineFunc)
+	}
+	for _, toFunc := range toFuncs {
+		if !slices.Contains(p.replacements[combineFunc], toFunc) {
+			p.replacements[combineFunc] = append(p.replacements[combineFunc], toFunc)
+		}
+	}
+	if len(p.replacements[combineFunc]) == 0 {
+		// all parts are nil. E.g. errors.Join()
+		// Keep empty replacement, it means combineFunc returns no error sources.
+		p.replacements[combineFunc] = []*ssa.Function{}
+	}
+	return nil
+}
+
+// partsScanner collects funcs that are source of combined errors.
+// It works like the call graph visitor, but it also expands nested combining calls.
+// E.g. multierror.Append(multierror.Append(nil, a), b)
+type partsScanner struct {
+	model         CombineModel
+	seen          map[*ssa.Call]struct{}
+	normalizeFunc func(fn *ssa.Function) (*ssa.Function, error)
+
+	toFuncs []*ssa.Function
+	isBad   bool
+}
+
+func (s *partsScanner) scanParts(parts []ssa.Value) error {
+	for _, part := range parts {
+		if !analysisutil.ImplementsError(part.Type()) {
+			// elements of slice can't be analyzed. E.g. errors.Join(errs...)
+			s.isBad = true
+			continue
+		}
+		index := 0
+		if extract, ok := part.(*ssa.Extract); ok {
+			index = extract.Index
+		}
+		s.normalizeFunc = norm.NewNormalizeFunc([]int{index})
+		if err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(s.createOptions()...), part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *partsScanner) VisitFunction(val *ssa.Function) error {
+	normed, err := s.normalizeFunc(val)
+	if err != nil {
+		return err
+	}
+	if normed != nil && !slices.Contains(s.toFuncs, normed) {
+		s.toFuncs = append(s.toFuncs, normed)
+	}
+	return nil
+}
+
+func (s *partsScanner) VisitConst(val *ssa.Const) error {
+	if !val.IsNil() || !analysisutil.ImplementsError(val.Type()) || s.model.NilIsBad {
+		s.isBad = true
+	}
+	return nil
+}
+
+func (s *partsScanner) VisitAlloc(_ *ssa.Alloc) error {
+	// usually alloc err is badFunc
+	s.isBad = true
+	return nil
+}
+
+func (s *partsScanner) VisitComplex(_ ssa.Value) error {
+	// can't analyze further due to complexity, treat badFunc.
+	s.isBad = true
+	return nil
+}
+
+func (s *partsScanner) VisitRecover(_ *ssa.Builtin) error {
+	// recovered value can't be tracked, treat badFunc.
+	s.isBad = true
+	return nil
+}
+
+func (s *partsScanner) VisitCallInvoke(_ *ssa.Call) error {
+	// can't analyze further due to interface method.
+	s.isBad = true
+	return nil
+}
+
+func (s *partsScanner) VisitCall(call *ssa.Call) error {
+	if _, ok := s.seen[call]; ok {
+		return nil
+	}
+	s.seen[call] = struct{}{}
+
+	if parts, ok := s.model.Parts(call); ok {
+		normalizeFunc := s.normalizeFunc
+		defer func() { s.normalizeFunc = normalizeFunc }()
+		return s.scanParts(parts)
+	}
+	// not combining call, walk called function like the call graph visitor.
+	return ssawalk.Walk(ssawalk.NewDefaultVisitorWith(s.createOptions()...), call.Call.Value)
+}
+
+func (s *partsScanner) createOptions() []ssawalk.Option {
+	return []ssawalk.Option{
+		ssawalk.WithVisitFunction(s.VisitFunction),
+		ssawalk.WithVisitConst(s.VisitConst),
+		ssawalk.WithVisitAlloc(s.VisitAlloc),
+		ssawalk.WithVisitComplex(s.VisitComplex),
+		ssawalk.WithVisitRecover(s.VisitRecover),
+		ssawalk.WithVisitCallInvoke(s.VisitCallInvoke),
+		ssawalk.WithVisitCall(s.VisitCall),
+	}
+}
@@ -0,0 +1,90 @@
+package pluginutil
+
+import (
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// IsFunc returns true if fn is defined in the package path (or its vendored copy),
+// its receiver type name is recv and its name is one of names.
+// recv is empty for package level functions.
+func IsFunc(fn *ssa.Function, path, recv string, names ...string) bool {
+	fnPath, fnName, fnRecv := funcInfo(fn)
+	if fnPath != path && !strings.HasSuffix(fnPath, "vendor/"+path) {
+		return false
+	}
+	return fnRecv == recv && slices.Contains(names, fnName)
+}
+
+// funcInfo returns package path, name and receiver type name of fn.
+// It uses types.Func because methods of imported types and instantiated functions may not have Pkg.
+func funcInfo(fn *ssa.Function) (path, name, recv string) {
+	if fn == nil {
+		return "", "", ""
+	}
+	obj, ok := fn.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return "", "", ""
+	}
+	return obj.Pkg().Path(), obj.Name(), recvName(obj.Signature())
+}
+
+// recvName returns the type name of sig's receiver.
+func recvName(sig *types.Signature) string {
+	recv := sig.Recv()
+	if recv == nil {
+		return ""
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// VariadicArgs returns elements passed to variadic parameter.
+// f(a, b) passes Slice of Alloc [2]T and a and b are stored to each IndexAddr of the Alloc.
+// If elements can't be determined (E.g. f(errs...)), it returns val itself.
+// Since val is not error, ScanCombine treats it as bad.
+func VariadicArgs(val ssa.Value) []ssa.Value {
+	if c, ok := val.(*ssa.Const); ok && c.IsNil() {
+		// f() passes nil slice.
+		return nil
+	}
+	slice, ok := val.(*ssa.Slice)
+	if !ok {
+		return []ssa.Value{val}
+	}
+	alloc, ok := slice.X.(*ssa.Alloc)
+	if !ok || alloc.Referrers() == nil {
+		return []ssa.Value{val}
+	}
+	var elems []ssa.Value
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Slice:
+			continue
+		case *ssa.IndexAddr:
+			if ref.Referrers() == nil {
+				continue
+			}
+			for _, instr := range *ref.Referrers() {
+				store, ok := instr.(*ssa.Store)
+				if !ok || store.Addr != ref {
+					return []ssa.Value{val}
+				}
+				elems = append(elems, store.Val)
+			}
+		default:
+			return []ssa.Value{val}
+		}
+	}
+	return elems
+}
@@ -0,0 +1,179 @@
+package pluginutil
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/rtn"
+	"github.com/cloverrose/rpcguard/passes/wraperr/visitors/call2func"
+	"github.com/cloverrose/rpcguard/passes/wraperr/visitors/norm"
+)
+
+// This file contains support for task groups like errgroup.Group.
+// A task group runs funcs passed by group.Go(f) and group.Wait() returns errors returned by those funcs.
+// ssa connects the caller to group.Wait, so ScanGroup replaces group.Wait with funcs passed to group.Go.
+
+// GroupModel describes methods of a task group.
+type GroupModel struct {
+	// IsWait returns true if fn returns errors of submitted funcs. E.g. (*errgroup.Group).Wait
+	IsWait func(fn *ssa.Function) bool
+
+	// IsGo returns true if fn submits func to group. E.g. (*errgroup.Group).Go
+	IsGo func(fn *ssa.Function) bool
+}
+
+// ScanGroup scans fn and returns replacements from group.Wait to funcs passed to group.Go for each return.
+func ScanGroup(
+	fn *ssa.Function,
+	indicesFunc func(fn *ssa.Function) []int,
+	model GroupModel,
+) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error) {
+	indices := indicesFunc(fn)
+	if len(indices) == 0 {
+		// srcFunc does not return error-ish values.
+		return nil, fmt.Errorf("no indices for fn: %s", fn)
+	}
+
+	rtnToReplacements := make(map[*ssa.Return]map[*ssa.Function][]*ssa.Function)
+	for _, val := range rtn.GetReturnsAt(fn, indices) {
+		plugin := &groupVisitorPlugin{
+			model:        model,
+			replacements: make(map[*ssa.Function][]*ssa.Function),
+		}
+		visitor := ssawalk.NewDefaultVisitorWith(ssawalk.WithVisitCall(plugin.VisitCall))
+		if err := ssawalk.Walk(visitor, val.Value); err != nil {
+			return nil, err
+		}
+		rtnToReplacements[val.Return] = plugin.replacements
+	}
+	return rtnToReplacements, nil
+}
+
+type groupVisitorPlugin struct {
+	model        GroupModel
+	replacements map[*ssa.Function][]*ssa.Function
+}
+
+func (p *groupVisitorPlugin) VisitCall(call *ssa.Call) error {
+	// Check if call is eg.Wait()
+	waitFunc, err := call2func.GetFuncFromCall(call)
+	if err != nil {
+		return err
+	}
+	if waitFunc == nil {
+		return nil
+	}
+	if !p.model.IsWait(waitFunc) {
+		return nil
+	}
+
+	// Find eg.Wait() receiver eg
+	receiver, err := getWaitReceiver(call.Call)
+	if err != nil {
+		return err
+	}
+
+	// Find eg.Go(func) funcs.
+	// The group can be passed to helpers, stored in struct fields or captured by closures,
+	// thus check referrers of all values that may refer to the same group.
+	aliases := Aliases(receiver)
+	for _, alias := range aliases {
+		refs := alias.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, instr := range *refs {
+			goCall, ok := instr.(*ssa.Call)
+			if !ok {
+				continue
+			}
+			goArgFuncs, err := p.getGoArgFuncs(goCall, aliases)
+			if err != nil {
+				return err
+			}
+			for _, goArgFunc := range goArgFuncs {
+				if !slices.Contains(p.replacements[waitFunc], goArgFunc) {
+					p.replacements[waitFunc] = append(p.replacements[waitFunc], goArgFunc)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *groupVisitorPlugin) getGoArgFuncs(egCall *ssa.Call, aliases []ssa.Value) ([]*ssa.Function, error) {
+	// Check if call is eg.Go()
+	goFunc, err := call2func.GetFuncFromCall(egCall)
+	if err != nil {
+		return nil, err
+	}
+	if goFunc == nil {
+		return nil, nil
+	}
+	if !p.model.IsGo(goFunc) {
+		return nil, nil
+	}
+
+	// Get eg.Go(fun) receiver and argument fun.
+	goReceiver, goArg, err := getGoArg(egCall.Call)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if eg.Go() receiver is the same group with eg.Wait() receiver.
+	// E.g. g.Go(f) where g is an argument of other call like addTasks(ctx, g).
+	if !slices.Contains(aliases, goReceiver) {
+		return nil, nil
+	}
+
+	// Find eg.Go(fun) argument funcs from goArg.
+	// goArg can be not *ssa.Function
+	//   goArg is *ssa.Function: eg.Go(fun): return fun.
+	//   goArg is *ssa.Call: eg.Go(higherOrderFun()): return higherOrderFun.
+	//   goArg is field: eg.Go(s.fun): return funcs stored to the field.
+	goArgFuncs, err := call2func.GetFuncsFromCall(goArg)
+	if err != nil {
+		return nil, err
+	}
+
+	// normalize
+	normalize := norm.NewNormalizeFunc([]int{0})
+	ret := make([]*ssa.Function, 0, len(goArgFuncs))
+	for _, goArgFunc := range goArgFuncs {
+		normed, err := normalize(goArgFunc)
+		if err != nil {
+			return nil, err
+		}
+		if normed != nil {
+			ret = append(ret, normed)
+		}
+	}
+	return ret, nil
+}
+
+// getGoArg returns eg.Go args receiver and func-ish value
+//
+//nolint:ireturn // interface ssa.Value is ok to return.
+func getGoArg(call ssa.CallCommon) (receiver, arg ssa.Value, err error) {
+	if len(call.Args) != 2 {
+		// eg.Go(f), eg.TryGo(f) <- Args should be [receiver, f]
+		return nil, nil, fmt.Errorf("expected 2 arguments, got %d", len(call.Args))
+	}
+	return call.Args[0], call.Args[1], nil
+}
+
+// getWaitReceiver returns eg.Wait receiver
+//
+//nolint:ireturn // interface ssa.Value is ok to return.
+func getWaitReceiver(call ssa.CallCommon) (ssa.Value, error) {
+	if len(call.Args) != 1 {
+		// eg.Wait() <- Args should be [receiver]
+		return nil, errors.New("unexpected: len(call.Args) != 1")
+	}
+	return call.Args[0], nil
+}
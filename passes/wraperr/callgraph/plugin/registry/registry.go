@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/conc"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/eg"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/errorsjoin"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/fmterrorf"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/multierr"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/multierror"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/pkgerrors"
+)
+
+const (
+	ModelErrGroup   = "errgroup"
+	ModelErrors     = "errors"
+	ModelMultierr   = "multierr"
+	ModelMultierror = "go-multierror"
+	ModelConc       = "conc"
+	ModelFmt        = "fmt"
+	ModelPkgErrors  = "pkg/errors"
+	modelsSeparator = ","
+)
+
+// Model is a propagation model of errors through a library.
+// Scan returns replacements of call graph for each return of fn.
+// E.g. errgroup model replaces (*errgroup.Group).Wait with funcs passed to (*errgroup.Group).Go.
+type Model struct {
+	Name string
+	Scan func(fn *ssa.Function, indicesFunc func(fn *ssa.Function) []int) (map[*ssa.Return]map[*ssa.Function][]*ssa.Function, error)
+}
+
+// registry holds all models. The order is the order of Scan.
+var registry = []Model{
+	{Name: ModelErrGroup, Scan: eg.Scan},
+	{Name: ModelErrors, Scan: errorsjoin.Scan},
+	{Name: ModelMultierr, Scan: multierr.Scan},
+	{Name: ModelMultierror, Scan: multierror.Scan},
+	{Name: ModelConc, Scan: conc.Scan},
+	{Name: ModelFmt, Scan: fmterrorf.Scan},
+	{Name: ModelPkgErrors, Scan: pkgerrors.Scan},
+}
+
+// Names returns names of all models.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, model := range registry {
+		names[i] = model.Name
+	}
+	return names
+}
+
+// Lookup returns models specified by comma separated names.
+// Returned models are sorted in the registry order.
+func Lookup(namesStr string) ([]Model, error) {
+	names := make(map[string]struct{})
+	for _, name := range strings.Split(namesStr, modelsSeparator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		names[name] = struct{}{}
+	}
+
+	models := make([]Model, 0, len(names))
+	for _, model := range registry {
+		if _, ok := names[model.Name]; ok {
+			models = append(models, model)
+			delete(names, model.Name)
+		}
+	}
+	for name := range names {
+		return nil, fmt.Errorf("unknown propagation model: %s (available: %s)", name, strings.Join(Names(), modelsSeparator))
+	}
+	return models, nil
+}
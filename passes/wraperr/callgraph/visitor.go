@@ -1,22 +1,49 @@
 package callgraph
 
 import (
-	"fmt"
+	"go/types"
+	"slices"
 
 	"golang.org/x/tools/go/ssa"
 
 	"github.com/gostaticanalysis/analysisutil"
 
 	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+	"github.com/cloverrose/rpcguard/pkg/signature"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/visitors/norm"
 )
 
 // visitorPlugin visit ssa.Value and collects ssa.Function that are source of returned value.
 // If it visits ssa.Value that is not func, ignore.
 type visitorPlugin struct {
+	owner         *ssa.Function
 	normalizeFunc func(fn *ssa.Function) (*ssa.Function, error)
+	seenCalls     map[*ssa.Call]struct{} // shared with plugins for arguments to stop at cyclic calls.
+
+	toFuncs      []*ssa.Function
+	isBad        bool
+	params       []int
+	parentParams []int
+	callSites    []*CallSite
+}
+
+func newVisitorPlugin(owner *ssa.Function, indices []int, seenCalls map[*ssa.Call]struct{}) *visitorPlugin {
+	return &visitorPlugin{
+		owner:         owner,
+		normalizeFunc: norm.NewNormalizeFunc(indices),
+		seenCalls:     seenCalls,
+	}
+}
 
-	toFuncs []*ssa.Function
-	isBad   bool
+func (p *visitorPlugin) source() *Source {
+	return &Source{
+		ToFuncs:        p.toFuncs,
+		IsObviouslyBad: p.isBad,
+		Params:         p.params,
+		ParentParams:   p.parentParams,
+		CallSites:      p.callSites,
+	}
 }
 
 func (p *visitorPlugin) VisitFunction(val *ssa.Function) error {
@@ -38,7 +65,7 @@ func (p *visitorPlugin) VisitConst(val *ssa.Const) error {
 		p.isBad = true
 	}
 	if !val.IsNil() {
-		panic(fmt.Sprintf("unexpected const %s\n", val.Name()))
+		return &ssawalk.UnsupportedError{Value: val, Reason: "unexpected non-nil const"}
 	}
 	return nil
 }
@@ -50,11 +77,87 @@ func (p *visitorPlugin) VisitAlloc(val *ssa.Alloc) error {
 }
 
 func (p *visitorPlugin) VisitComplex(val ssa.Value) error {
+	if param, ok := val.(*ssa.Parameter); ok && isFuncParam(param) {
+		// func is given by caller, it is judged at each call site.
+		switch param.Parent() {
+		case p.owner:
+			p.params = append(p.params, slices.Index(p.owner.Params, param))
+			return nil
+		case p.owner.Parent():
+			// owner is closure and it captures parameter of enclosing function.
+			p.parentParams = append(p.parentParams, slices.Index(p.owner.Parent().Params, param))
+			return nil
+		}
+	}
 	// can't analyze further due to complexity, treat badFunc.
 	p.isBad = true
 	return nil
 }
 
+func (p *visitorPlugin) VisitRecover(_ *ssa.Builtin) error {
+	// recovered value is passed to panic somewhere, it can't be tracked, treat badFunc.
+	p.isBad = true
+	return nil
+}
+
+// isFuncParam returns true if type of param is func returns error.
+func isFuncParam(param *ssa.Parameter) bool {
+	sig, ok := param.Type().Underlying().(*types.Signature)
+	return ok && len(signature.ErrIshResultIndices(sig)) != 0
+}
+
+func (p *visitorPlugin) VisitCall(val *ssa.Call) error {
+	if _, ok := p.seenCalls[val]; ok {
+		return nil
+	}
+	p.seenCalls[val] = struct{}{}
+
+	if err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(p.createOptions()...), val.Call.Value); err != nil {
+		return err
+	}
+	callSite, err := p.scanCallSite(val)
+	if err != nil {
+		return err
+	}
+	if callSite != nil {
+		p.callSites = append(p.callSites, callSite)
+	}
+	return nil
+}
+
+// scanCallSite scans function-typed arguments of static call.
+// It returns nil when callee is unknown or arguments don't match with callee's parameters (e.g. bound method).
+func (p *visitorPlugin) scanCallSite(call *ssa.Call) (*CallSite, error) {
+	callee := call.Call.StaticCallee()
+	if callee == nil {
+		return nil, nil
+	}
+	normed, err := p.normalizeFunc(callee)
+	if err != nil {
+		return nil, err
+	}
+	if normed == nil || len(normed.Params) != len(call.Call.Args) {
+		return nil, nil
+	}
+	args := make(map[int]*Source)
+	for i, arg := range call.Call.Args {
+		sig, ok := arg.Type().Underlying().(*types.Signature)
+		if !ok {
+			continue
+		}
+		indices := signature.ErrIshResultIndices(sig)
+		if len(indices) == 0 {
+			continue
+		}
+		plugin := newVisitorPlugin(p.owner, indices, p.seenCalls)
+		if err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(plugin.createOptions()...), arg); err != nil {
+			return nil, err
+		}
+		args[i] = plugin.source()
+	}
+	return &CallSite{Callee: normed, Args: args}, nil
+}
+
 func (p *visitorPlugin) VisitCallInvoke(val *ssa.Call) error {
 	// can't analyze further due to interface method.
 	// However, interface method is usually defined in different package.
@@ -69,6 +172,8 @@ func (p *visitorPlugin) createOptions() []ssawalk.Option {
 		ssawalk.WithVisitConst(p.VisitConst),
 		ssawalk.WithVisitAlloc(p.VisitAlloc),
 		ssawalk.WithVisitComplex(p.VisitComplex),
+		ssawalk.WithVisitRecover(p.VisitRecover),
+		ssawalk.WithVisitCall(p.VisitCall),
 		ssawalk.WithVisitCallInvoke(p.VisitCallInvoke),
 	}
 }
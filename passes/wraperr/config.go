@@ -3,12 +3,15 @@ package wraperr
 import (
 	"github.com/cloverrose/rpcguard/pkg/filter"
 	"github.com/cloverrose/rpcguard/pkg/logger"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/registry"
 )
 
-// log related configuration.
+// log related configuration. See logger.Config.
 var LogConfig = logger.Config{
-	Level:  "INFO",
+	Level:  "WARN",
 	File:   "",
+	Dir:    "",
 	Format: "json",
 }
 
@@ -26,8 +29,11 @@ const (
 var ReportMode = reportModeReturn
 
 // IncludePackages is configuration which packages should be included.
-// Multiple Packages can be specified by using commas (,).
-// e.g. github.com/foo/bar/a/includedpkg/hello,github.com/foo/bar/common
+// Multiple Packages can be specified by using commas (,) or a JSON array.
+// Each pattern is a regexp or a Go package pattern (./..., github.com/foo/bar/...), and "!" negates a pattern.
+// e.g. github.com/foo/bar/a/includedpkg/hello,github.com/foo/bar/common/...,!./internal/mock/...
+// If it is empty, all packages of the main module of the analyzed package are included
+// (and all modules used by go.work if the module is in a workspace), like "$(go list -m)/.*".
 //
 // [Explain with example]
 // There are two packages,
@@ -53,14 +59,64 @@ var ExcludePackages = ""
 // -rpc_wraperr.ExcludeFiles='.+_test\.go,.+\.connect\.go'
 var ExcludeFiles = `.+_test\.go,.+\.connect\.go`
 
+// SkipGenerated is configuration whether skip RPC methods in generated files.
+// Generated files have the standard header "// Code generated ... DO NOT EDIT." (e.g. *.pb.go, mockgen, sqlc).
+// Functions in generated files are still analyzed and their facts are exported, so that callers are judged correctly.
+// Default is true.
+var SkipGenerated = true
+
+// StrictUnknown is configuration whether report errors that cannot be proven.
+// When a callee is defined in a package of the same module but the package is not in IncludePackages,
+// wraperr can't tell whether the callee's error is connect.NewError or not.
+// Such a func is marked as unknown instead of bad, and the unknown verdict propagates to its callers.
+// - true: Report RPC methods returning unknown errors with a message that the callee pkg is not in IncludePackages.
+// - false: Don't report them. Bad errors are still reported.
+// Default is true. It is usually a sign that IncludePackages is too narrow.
+var StrictUnknown = true
+
 // EnableErrGroupAnalyzer is configuration whether enable ErrGroupAnalyzer.
 // Default is true and recommend to keep true.
-// The reason for this configuration item is to share with users that
-// wraperr can be incorrect in a false positive sense for propagation of errors that it does not support.
-// errgroup is supported, but others such as hashicorp/go-multierror is not supported.
+// If false, errgroup model is disabled even if PropagationModels includes it.
 var EnableErrGroupAnalyzer = true
 
-var (
-	packageFilter *filter.Filter
-	fileFilter    *filter.Filter
-)
+// PropagationModels is configuration which error propagation models are enabled.
+// Multiple models can be specified by using commas (,).
+// Without a model, wraperr treats errors propagated through the library as bad, because the library is defined in other package.
+// - errgroup: golang.org/x/sync/errgroup. Wait() returns errors of funcs passed to Go() or TryGo().
+// - errors: errors.Join(errs...) is ok only if all errs are ok.
+// - multierr: go.uber.org/multierr Append and Combine are ok only if all combined errors are ok.
+// - go-multierror: github.com/hashicorp/go-multierror Append and ErrorOrNil are ok only if all appended errors are ok.
+// - conc: github.com/sourcegraph/conc/pool ErrorPool and ContextPool Wait() returns errors of funcs passed to Go().
+// - fmt: fmt.Errorf is ok only if all operands of %w are ok. fmt.Errorf without %w is bad.
+// - pkg/errors: github.com/pkg/errors WithStack, Wrap, Wrapf, WithMessage and WithMessagef are ok only if the wrapped error is ok.
+// wraperr can be incorrect in a false positive sense for propagation of errors that it does not support.
+var PropagationModels = "errgroup,errors,multierr,go-multierror,conc,fmt,pkg/errors"
+
+// DumpGraph is configuration of directory to write the error call graph of each package for debugging.
+// It is written after marking, so it has funcs, edges of each return, SCCs, facts and substitutions by propagation models.
+// File name is the package path escaped by url.PathEscape, e.g. github.com%2Ffoo%2Fbar.dot.
+// Default is empty, it doesn't write the graph.
+var DumpGraph = ""
+
+// DumpGraphFormat is configuration of the format of DumpGraph.
+// Multiple formats can be specified by using commas (,).
+// - dot: Graphviz DOT. Render it with `dot -Tsvg github.com%2Ffoo%2Fbar.dot -o graph.svg`.
+// - json: structured JSON.
+var DumpGraphFormat = "dot"
+
+// StatsDir is configuration of directory to write timing and statistics of each package.
+// It has durations of phases (e.g. callgraph, model:errgroup, mark) and counters
+// (e.g. funcs scanned, graph edges, SCC sizes, facts exported and imported, local fact hits).
+// File name is the analyzer name and the package path escaped by url.PathEscape, e.g. rpc_wraperr.github.com%2Ffoo%2Fbar.json.
+// Default is empty, it doesn't record statistics.
+var StatsDir = ""
+
+// passTargets holds filters and propagation models built from the configuration for each pass.
+// It is passed explicitly instead of package variables, since passes of packages run concurrently.
+type passTargets struct {
+	// modules holds paths of the target modules (See filter.TargetModules).
+	modules  []string
+	packages *filter.Filter
+	files    *filter.Filter
+	models   []registry.Model
+}
@@ -0,0 +1,281 @@
+package wraperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph"
+)
+
+const (
+	dumpFormatDOT  = "dot"
+	dumpFormatJSON = "json"
+)
+
+// graphDump is the error call graph of a package written by DumpGraph.
+type graphDump struct {
+	Package string      `json:"package"`
+	Funcs   []*funcDump `json:"funcs"`
+	// SCCs are strongly connected components in the order of marking (callees first).
+	SCCs [][]string `json:"sccs"`
+}
+
+type funcDump struct {
+	Name        string        `json:"name"`
+	Pos         string        `json:"pos,omitempty"`
+	External    bool          `json:"external,omitempty"` // defined in other package or not scanned, its fact is imported.
+	RPCMethod   bool          `json:"rpcMethod,omitempty"`
+	Unsupported bool          `json:"unsupported,omitempty"`
+	SCC         int           `json:"scc"`            // index of SCCs. -1 if the func has no edges.
+	Kind        string        `json:"kind,omitempty"` // kind of the fact. ok, bad or unknown. Empty if there is no fact.
+	Fact        string        `json:"fact,omitempty"`
+	Returns     []*returnDump `json:"returns,omitempty"`
+}
+
+type returnDump struct {
+	Pos          string   `json:"pos"`
+	Line         int      `json:"line"`
+	ObviouslyBad bool     `json:"obviouslyBad,omitempty"` // returns error which is not from funcs. e.g. errors.New
+	ToFuncs      []string `json:"toFuncs,omitempty"`
+	// ArgFuncs are funcs passed to callees whose results are returned. They are judged before the func.
+	ArgFuncs      []string            `json:"argFuncs,omitempty"`
+	Substitutions []*substitutionDump `json:"substitutions,omitempty"`
+}
+
+// substitutionDump is a replacement of ToFuncs by a propagation model. e.g. eg.Wait -> funcs passed to eg.Go
+type substitutionDump struct {
+	Model string   `json:"model"`
+	From  string   `json:"from"`
+	To    []string `json:"to"`
+}
+
+// dumpGraph writes the error call graph of the package to DumpGraph directory in DumpGraphFormat.
+func dumpGraph(pass *analysis.Pass, cg *callgraph.CallGraph, sccs [][]*ssa.Function,
+	factWrapper *factutil.FactWrapper[*isErrorHandler], rpcChecker *rpcmethod.Checker,
+) error {
+	if DumpGraph == "" {
+		return nil
+	}
+	d := buildGraphDump(pass, cg, sccs, factWrapper, rpcChecker)
+	if err := os.MkdirAll(DumpGraph, 0o755); err != nil {
+		return err
+	}
+	// package path can't be a file name as is, escape it not to collide (e.g. a/b_c and a_b/c). e.g. github.com/foo/bar -> github.com%2Ffoo%2Fbar
+	base := filepath.Join(DumpGraph, url.PathEscape(pass.Pkg.Path()))
+	for _, format := range strings.Split(DumpGraphFormat, ",") {
+		var data []byte
+		switch format = strings.TrimSpace(format); format {
+		case dumpFormatDOT:
+			data = []byte(d.dot())
+		case dumpFormatJSON:
+			var err error
+			if data, err = json.MarshalIndent(d, "", "  "); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown DumpGraphFormat %q", format)
+		}
+		if err := os.WriteFile(base+"."+format, data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildGraphDump(pass *analysis.Pass, cg *callgraph.CallGraph, sccs [][]*ssa.Function,
+	factWrapper *factutil.FactWrapper[*isErrorHandler], rpcChecker *rpcmethod.Checker,
+) *graphDump {
+	position := func(pos token.Pos) string {
+		if !pos.IsValid() {
+			return ""
+		}
+		return pass.Fset.Position(pos).String()
+	}
+	d := &graphDump{Package: pass.Pkg.Path(), SCCs: make([][]string, len(sccs))}
+	sccIndex := make(map[*ssa.Function]int)
+	for i, scc := range sccs {
+		d.SCCs[i] = funcNames(scc)
+		for _, fn := range scc {
+			sccIndex[fn] = i
+		}
+	}
+
+	// scanned funcs first, then the others (callees) in the order of SCCs.
+	funcs := slices.Clone(cg.Funcs())
+	for _, scc := range sccs {
+		for _, fn := range scc {
+			if cg.GetReturnInfo(fn) == nil {
+				funcs = append(funcs, fn)
+			}
+		}
+	}
+	for _, fn := range funcs {
+		fd := &funcDump{Name: fn.String(), Pos: position(fn.Pos()), SCC: -1}
+		if i, ok := sccIndex[fn]; ok {
+			fd.SCC = i
+		}
+		if fact, ok := factWrapper.Import(fn); ok {
+			fd.Kind = fact.Kind.String()
+			fd.Fact = fact.String()
+		}
+		fd.RPCMethod = rpcChecker != nil && rpcChecker.IsRPCMethod(fn)
+		info := cg.GetReturnInfo(fn)
+		if info == nil {
+			fd.External = true
+			d.Funcs = append(d.Funcs, fd)
+			continue
+		}
+		fd.Unsupported = info.IsUnsupported()
+		returns := info.GetReturns()
+		slices.SortFunc(returns, func(a, b *ssa.Return) int { return int(a.Pos() - b.Pos()) })
+		for _, rt := range returns {
+			src := info.GetSource(rt)
+			rd := &returnDump{
+				Pos:          position(rt.Pos()),
+				Line:         pass.Fset.Position(rt.Pos()).Line,
+				ObviouslyBad: src.IsObviouslyBad,
+				ToFuncs:      funcNames(src.ToFuncs),
+				ArgFuncs:     funcNames(src.ArgToFuncs()),
+			}
+			for _, sub := range src.Substitutions {
+				rd.Substitutions = append(rd.Substitutions, &substitutionDump{Model: sub.Model, From: sub.From.String(), To: funcNames(sub.To)})
+			}
+			fd.Returns = append(fd.Returns, rd)
+		}
+		d.Funcs = append(d.Funcs, fd)
+	}
+	return d
+}
+
+func funcNames(funcs []*ssa.Function) []string {
+	if len(funcs) == 0 {
+		return nil
+	}
+	names := make([]string, len(funcs))
+	for i, fn := range funcs {
+		names[i] = fn.String()
+	}
+	return names
+}
+
+// dot returns the graph in Graphviz DOT language.
+// Funcs are colored by kind of the fact, RPC methods have double border, and SCCs with several funcs are clustered.
+// Edges are labeled with the line of the return and the propagation model if it is substituted.
+func (d *graphDump) dot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(d.Package))
+	b.WriteString("\tnode [shape=box, style=filled, fillcolor=white];\n")
+	b.WriteString("\tbad [label=\"non connect error\", shape=octagon, fillcolor=\"#ea9999\"];\n")
+
+	ids := make(map[string]string, len(d.Funcs))
+	for i, fd := range d.Funcs {
+		ids[fd.Name] = "n" + strconv.Itoa(i)
+	}
+	id := func(name string) string {
+		if _, ok := ids[name]; !ok {
+			// not scanned and not in SCCs. It should not happen, but keep the edge.
+			ids[name] = "n" + strconv.Itoa(len(ids))
+		}
+		return ids[name]
+	}
+
+	clusters := make(map[int][]string)
+	for _, fd := range d.Funcs {
+		label := fd.Name
+		if fd.Fact != "" {
+			label += "\n" + fd.Fact
+		}
+		if fd.Unsupported {
+			label += "\n(unsupported)"
+		}
+		attrs := []string{"label=" + strconv.Quote(label), "fillcolor=" + strconv.Quote(kindColor(fd.Kind))}
+		if fd.RPCMethod {
+			attrs = append(attrs, "peripheries=2")
+		}
+		if fd.External {
+			attrs = append(attrs, "style=\"filled,dashed\"")
+		}
+		if fd.Pos != "" {
+			attrs = append(attrs, "tooltip="+strconv.Quote(fd.Pos))
+		}
+		if fd.SCC >= 0 && len(d.SCCs[fd.SCC]) > 1 {
+			clusters[fd.SCC] = append(clusters[fd.SCC], id(fd.Name))
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", id(fd.Name), strings.Join(attrs, ", "))
+	}
+	for i := range d.SCCs {
+		members, ok := clusters[i]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\tsubgraph cluster_scc%d {\n\t\tlabel=\"SCC %d\";\n\t\t%s;\n\t}\n", i, i, strings.Join(members, "; "))
+	}
+
+	type edge struct{ from, to, label, style string }
+	var edges []edge
+	seen := make(map[edge]bool)
+	addEdge := func(e edge) {
+		if !seen[e] {
+			seen[e] = true
+			edges = append(edges, e)
+		}
+	}
+	for _, fd := range d.Funcs {
+		for _, rd := range fd.Returns {
+			line := "return"
+			if rd.Line != 0 {
+				line = "L" + strconv.Itoa(rd.Line)
+			}
+			if rd.ObviouslyBad {
+				addEdge(edge{from: id(fd.Name), to: "bad", label: line, style: "solid"})
+			}
+			models := make(map[string]string)
+			for _, sub := range rd.Substitutions {
+				for _, to := range sub.To {
+					models[to] = sub.Model
+				}
+			}
+			for _, to := range rd.ToFuncs {
+				label := line
+				if model, ok := models[to]; ok {
+					label += " via " + model
+				}
+				addEdge(edge{from: id(fd.Name), to: id(to), label: label, style: "solid"})
+			}
+			for _, to := range rd.ArgFuncs {
+				addEdge(edge{from: id(fd.Name), to: id(to), label: line + " arg", style: "dashed"})
+			}
+		}
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s, style=%s];\n", e.from, e.to, strconv.Quote(e.label), e.style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func kindColor(kind string) string {
+	switch kind {
+	case KindOK.String():
+		return "#d9ead3"
+	case KindBad.String():
+		return "#f4cccc"
+	case KindUnknown.String():
+		return "#fff2cc"
+	default:
+		return "white"
+	}
+}
@@ -1,5 +1,10 @@
 package wraperr
 
+import (
+	"strconv"
+	"strings"
+)
+
 type Kind uint32
 
 const (
@@ -8,8 +13,29 @@ const (
 	KindBad
 )
 
+func (k Kind) String() string {
+	switch k {
+	case KindUnknown:
+		return "unknown"
+	case KindOK:
+		return "ok"
+	case KindBad:
+		return "bad"
+	default:
+		panic("unreachable")
+	}
+}
+
 type isErrorHandler struct {
 	Kind Kind
+	// Params holds indices of function-typed parameters whose result is returned.
+	// KindOK func with Params is ok only when functions passed to these parameters are ok.
+	Params []int
+	// ParentParams holds indices of function-typed parameters of enclosing function
+	// whose result is returned by this closure.
+	ParentParams []int
+	// Packages holds paths of packages not in IncludePackages which make KindUnknown func unprovable.
+	Packages []string
 }
 
 func (f *isErrorHandler) AFact() {}
@@ -17,8 +43,21 @@ func (f *isErrorHandler) AFact() {}
 func (f *isErrorHandler) String() string {
 	switch f.Kind {
 	case KindUnknown:
+		if len(f.Packages) != 0 {
+			return "unknownFunc(pkg:" + strings.Join(f.Packages, ",") + ")"
+		}
 		return "unknownFunc"
 	case KindOK:
+		var conds []string
+		if len(f.Params) != 0 {
+			conds = append(conds, "param:"+joinInts(f.Params))
+		}
+		if len(f.ParentParams) != 0 {
+			conds = append(conds, "parentParam:"+joinInts(f.ParentParams))
+		}
+		if len(conds) != 0 {
+			return "okFunc(" + strings.Join(conds, " ") + ")"
+		}
 		return "okFunc"
 	case KindBad:
 		return "badFunc"
@@ -26,3 +65,11 @@ func (f *isErrorHandler) String() string {
 		panic("unreachable")
 	}
 }
+
+func joinInts(values []int) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
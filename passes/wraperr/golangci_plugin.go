@@ -27,7 +27,13 @@ type settings struct {
 	IncludePackages        string
 	ExcludePackages        string
 	ExcludeFiles           string
+	SkipGenerated          *bool
 	EnableErrGroupAnalyzer bool
+	StrictUnknown          *bool
+	PropagationModels      string
+	DumpGraph              string
+	DumpGraphFormat        string
+	StatsDir               string
 }
 
 type plugin struct {
@@ -41,6 +47,9 @@ func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
 	if p.settings.Log.File != "" {
 		LogConfig.File = p.settings.Log.File
 	}
+	if p.settings.Log.Dir != "" {
+		LogConfig.Dir = p.settings.Log.Dir
+	}
 	if p.settings.Log.Format != "" {
 		LogConfig.Format = p.settings.Log.Format
 	}
@@ -56,6 +65,24 @@ func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
 	if p.settings.ExcludeFiles != "" {
 		ExcludeFiles = p.settings.ExcludeFiles
 	}
+	if p.settings.SkipGenerated != nil {
+		SkipGenerated = *p.settings.SkipGenerated
+	}
+	if p.settings.StrictUnknown != nil {
+		StrictUnknown = *p.settings.StrictUnknown
+	}
+	if p.settings.PropagationModels != "" {
+		PropagationModels = p.settings.PropagationModels
+	}
+	if p.settings.DumpGraph != "" {
+		DumpGraph = p.settings.DumpGraph
+	}
+	if p.settings.DumpGraphFormat != "" {
+		DumpGraphFormat = p.settings.DumpGraphFormat
+	}
+	if p.settings.StatsDir != "" {
+		StatsDir = p.settings.StatsDir
+	}
 	return []*analysis.Analyzer{
 		Analyzer,
 	}, nil
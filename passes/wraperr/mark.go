@@ -19,61 +19,114 @@ type factImporter interface {
 	Import(fn *ssa.Function) (*isErrorHandler, bool)
 }
 
+// verdict is the result of checking error sources.
+// Bad beats unknown, and unknown beats ok.
+type verdict struct {
+	kind Kind
+	// packages holds paths of packages not in IncludePackages which make the verdict unknown.
+	packages []string
+}
+
+var (
+	verdictOK  = verdict{kind: KindOK}
+	verdictBad = verdict{kind: KindBad}
+)
+
+func verdictUnknown(packages ...string) verdict {
+	return verdict{kind: KindUnknown, packages: packages}
+}
+
+// verdictOf returns verdict recorded in fact.
+func verdictOf(fact *isErrorHandler) verdict {
+	switch fact.Kind {
+	case KindUnknown:
+		return verdictUnknown(fact.Packages...)
+	case KindBad:
+		return verdictBad
+	case KindOK:
+		return verdictOK
+	default:
+		panic("unreachable")
+	}
+}
+
+func (v verdict) isBad() bool {
+	return v.kind == KindBad
+}
+
+// merge returns the worse verdict of v and other.
+func (v verdict) merge(other verdict) verdict {
+	switch {
+	case v.kind == KindBad || other.kind == KindBad:
+		return verdictBad
+	case v.kind == KindUnknown || other.kind == KindUnknown:
+		packages := slices.Concat(v.packages, other.packages)
+		slices.Sort(packages)
+		return verdictUnknown(slices.Compact(packages)...)
+	default:
+		return verdictOK
+	}
+}
+
 // markSCCs checks and records facts.
-func markSCCs(pass *analysis.Pass, sccs [][]*ssa.Function, factWrapper *factutil.FactWrapper[*isErrorHandler],
-	cg *callgraph.CallGraph,
-) error {
+// It returns how many functions each package not in IncludePackages made unknown.
+func markSCCs(pass *analysis.Pass, log *slog.Logger, targets *passTargets, sccs [][]*ssa.Function,
+	factWrapper *factutil.FactWrapper[*isErrorHandler], cg *callgraph.CallGraph,
+) (unknownSummary, error) {
+	summary := make(unknownSummary)
 	for _, scc := range sccs {
-		bad, err := checkSCC(pass, scc, factWrapper, cg)
+		v, err := checkSCC(pass, log, targets, scc, factWrapper, cg)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		propagateMarkToSCC(log, scc, v, factWrapper, cg)
+		for _, pkg := range v.packages {
+			summary[pkg] += len(scc)
 		}
-		propagateMarkToSCC(scc, bad, factWrapper)
 	}
-	return nil
+	return summary, nil
 }
 
-// checkSCC checks if given scc has bad error sources or not.
+// checkSCC checks if given scc has bad or unknown error sources or not.
 func checkSCC(
 	pass *analysis.Pass,
+	log *slog.Logger,
+	targets *passTargets,
 	scc []*ssa.Function,
 	factWrapper factImporter,
 	cg *callgraph.CallGraph,
-) (bool, error) {
+) (verdict, error) {
+	v := verdictOK
 	for _, fromFunc := range scc {
-		bad, err := checkFunc(pass, factWrapper, fromFunc, scc, cg)
+		fv, err := checkFunc(pass, log, targets, factWrapper, fromFunc, scc, cg)
 		if err != nil {
-			return false, err
+			return verdict{}, err
 		}
-		if bad {
-			return true, nil
+		v = v.merge(fv)
+		if v.isBad() {
+			return v, nil
 		}
 	}
-	return false, nil
+	return v, nil
 }
 
 func checkFunc(
 	pass *analysis.Pass,
+	log *slog.Logger,
+	targets *passTargets,
 	factWrapper factImporter,
 	srcFunc *ssa.Function,
 	scc []*ssa.Function,
 	cg *callgraph.CallGraph,
-) (bool, error) {
-	slog.Debug("check srcFunc", logger.Attr(srcFunc))
+) (verdict, error) {
+	log.Debug("check srcFunc", logger.Attr(srcFunc))
 
 	fact, ok := factWrapper.Import(srcFunc)
 	if ok {
-		switch fact.Kind {
-		case KindUnknown:
-			panic(unexpectedUnknown)
-		case KindBad:
-			return true, nil
-		case KindOK:
-			return false, nil
-		}
+		return verdictOf(fact), nil
 	}
 	if isConnectNewError(srcFunc) {
-		return false, nil
+		return verdictOK, nil
 	}
 	if srcFunc == nil {
 		panic("unexpected srcFunc is nil")
@@ -81,56 +134,161 @@ func checkFunc(
 	if srcFunc.Pkg == nil {
 		// This happens when interface method is assigned to local variable.
 		// E.g. fn := app.handler.Handle
-		slog.Debug("found bad func (srcFunc.Pkg is nil)", logger.Attr(srcFunc))
-		return true, nil
+		log.Debug("found bad func (srcFunc.Pkg is nil)", logger.Attr(srcFunc))
+		return verdictBad, nil
 	}
 	if srcFunc.Pkg.Pkg != pass.Pkg {
-		// srcFunc is defined in different package.
-		// and fact is unknown, so it is unknown bad func.
-		slog.Debug("found bad func (srcFunc.Pkg.Pkg != pass.Pkg)", logger.Attr(srcFunc))
-		return true, nil
+		// srcFunc is defined in different package, and fact is unknown.
+		path := srcFunc.Pkg.Pkg.Path()
+		if isExcludedModulePackage(targets, path) {
+			// srcFunc is in the same module but not analyzed, so we can't prove it is ok or bad.
+			log.Debug("found unknown func (srcFunc.Pkg is not in IncludePackages)", logger.Attr(srcFunc))
+			return verdictUnknown(path), nil
+		}
+		log.Debug("found bad func (srcFunc.Pkg.Pkg != pass.Pkg)", logger.Attr(srcFunc))
+		return verdictBad, nil
 	}
 
-	var bad bool
+	v := verdictOK
 	info := cg.GetReturnInfo(srcFunc)
 	if info == nil {
 		panic(fmt.Sprintf("unexpected info not found for srcFunc: %s", srcFunc.Name()))
 	}
-	for _, toFunc := range info.GetAllToFuncs() {
+	for _, rtn := range info.GetReturns() {
+		v = v.merge(checkSource(log, srcFunc, info.GetSource(rtn), scc, factWrapper))
+		if v.isBad() {
+			break
+		}
+	}
+	log.Debug("func is checked", logger.Attr(srcFunc), slog.String("kind", v.kind.String()))
+	return v, nil
+}
+
+// isExcludedModulePackage returns true if path is a package of the target modules but not in IncludePackages.
+// Such a package is typically a misconfiguration of IncludePackages.
+// Vendored packages belong to other modules.
+func isExcludedModulePackage(targets *passTargets, path string) bool {
+	if !slices.ContainsFunc(targets.modules, func(module string) bool {
+		return path == module || strings.HasPrefix(path, module+"/")
+	}) {
+		return false
+	}
+	if strings.Contains(path, "/vendor/") {
+		return false
+	}
+	return !targets.packages.IsTarget(path)
+}
+
+// checkSource checks if src of owner has bad or unknown error sources or not.
+// Functions in scc are skipped since they are checked together.
+//
+//nolint:gocognit,cyclop // check each kind of sources.
+func checkSource(log *slog.Logger, owner *ssa.Function, src *callgraph.Source, scc []*ssa.Function, factWrapper factImporter) verdict {
+	if src.IsObviouslyBad {
+		return verdictBad
+	}
+	v := verdictOK
+	for _, toFunc := range src.ToFuncs {
 		if slices.Contains(scc, toFunc) {
-			slog.Debug("toFunc is in the same SCC", logger.Attr(toFunc))
+			log.Debug("toFunc is in the same SCC", logger.Attr(toFunc))
 			continue
 		}
 		if isConnectNewError(toFunc) {
 			continue
 		}
-		bad = checkBad(toFunc, factWrapper)
-		if bad {
-			break
+		v = v.merge(checkToFunc(toFunc, factWrapper))
+		if v.isBad() {
+			return v
+		}
+		if len(calleeParams(toFunc, factWrapper)) != 0 && !slices.ContainsFunc(src.CallSites, func(callSite *callgraph.CallSite) bool {
+			return callSite.Callee == toFunc
+		}) {
+			// toFunc returns result of its parameter, but it is not called here (e.g. returned as value).
+			// Then arguments are unknown.
+			log.Debug("found bad func (arguments are unknown)", logger.Attr(toFunc))
+			return verdictBad
+		}
+		if len(parentParams(toFunc, factWrapper)) != 0 && toFunc.Parent() != owner {
+			// toFunc is closure captures parameters of its enclosing function, but it is not evaluated there.
+			log.Debug("found bad func (captured parameters are unknown)", logger.Attr(toFunc))
+			return verdictBad
 		}
 	}
-	if bad {
-		slog.Debug("func is bad func", logger.Attr(srcFunc))
-	} else {
-		slog.Debug("func is not bad (still suspicious)", logger.Attr(srcFunc))
+	for _, callSite := range src.CallSites {
+		for _, i := range calleeParams(callSite.Callee, factWrapper) {
+			arg, ok := callSite.Args[i]
+			if !ok {
+				log.Debug("found bad func (bad argument)", logger.Attr(callSite.Callee), slog.Int("param", i))
+				return verdictBad
+			}
+			v = v.merge(checkSource(log, owner, arg, scc, factWrapper))
+			if v.isBad() {
+				log.Debug("found bad func (bad argument)", logger.Attr(callSite.Callee), slog.Int("param", i))
+				return v
+			}
+		}
+	}
+	return v
+}
+
+// calleeParams returns indices of callee's function-typed parameters whose result is returned.
+func calleeParams(callee *ssa.Function, factWrapper factImporter) []int {
+	fact, ok := factWrapper.Import(callee)
+	if !ok || fact.Kind != KindOK {
+		return nil
+	}
+	return fact.Params
+}
+
+// parentParams returns indices of function-typed parameters of closure's enclosing function whose result is returned.
+func parentParams(closure *ssa.Function, factWrapper factImporter) []int {
+	fact, ok := factWrapper.Import(closure)
+	if !ok || fact.Kind != KindOK {
+		return nil
 	}
-	return bad, nil
+	return fact.ParentParams
 }
 
-func checkBad(toFunc *ssa.Function, factWrapper factImporter) bool {
+// collectParams appends indices of owner's function-typed parameters whose result is src.
+// It includes parameters passed to callees and captured by closures which return results of them.
+func collectParams(owner *ssa.Function, src *callgraph.Source, factWrapper factImporter, params []int) []int {
+	params = append(params, src.Params...)
+	for _, toFunc := range src.ToFuncs {
+		if toFunc.Parent() == owner {
+			params = append(params, parentParams(toFunc, factWrapper)...)
+		}
+	}
+	for _, callSite := range src.CallSites {
+		for _, i := range calleeParams(callSite.Callee, factWrapper) {
+			if arg, ok := callSite.Args[i]; ok {
+				params = collectParams(owner, arg, factWrapper, params)
+			}
+		}
+	}
+	return params
+}
+
+// collectParentParams appends indices of function-typed parameters of owner's enclosing function whose result is src.
+func collectParentParams(src *callgraph.Source, factWrapper factImporter, params []int) []int {
+	params = append(params, src.ParentParams...)
+	for _, callSite := range src.CallSites {
+		for _, i := range calleeParams(callSite.Callee, factWrapper) {
+			if arg, ok := callSite.Args[i]; ok {
+				params = collectParentParams(arg, factWrapper, params)
+			}
+		}
+	}
+	return params
+}
+
+// checkToFunc returns verdict of toFunc recorded in facts.
+func checkToFunc(toFunc *ssa.Function, factWrapper factImporter) verdict {
 	fact, ok := factWrapper.Import(toFunc)
 	if ok {
-		switch fact.Kind {
-		case KindUnknown:
-			panic(unexpectedUnknown)
-		case KindBad:
-			return true
-		case KindOK:
-			return false
-		}
+		return verdictOf(fact)
 	}
 	// toFunc is not in facts => badFunc
-	return true
+	return verdictBad
 }
 
 // isConnectNewError returns true if fn is connect.NewError.
@@ -139,18 +297,45 @@ func isConnectNewError(fn *ssa.Function) bool {
 		return false
 	}
 	path := fn.Pkg.Pkg.Path()
-	const connectPath = "connectrpc.com/connect"
 	return (path == connectPath || strings.HasSuffix(path, "vendor/"+connectPath)) && fn.Name() == "NewError"
 }
 
-func propagateMarkToSCC(scc []*ssa.Function, bad bool, factWrapper *factutil.FactWrapper[*isErrorHandler]) {
+func propagateMarkToSCC(
+	log *slog.Logger,
+	scc []*ssa.Function,
+	v verdict,
+	factWrapper *factutil.FactWrapper[*isErrorHandler],
+	cg *callgraph.CallGraph,
+) {
 	for _, fn := range scc {
-		slog.Debug("propagate mark", logger.Attr(fn), slog.Bool("bad", bad))
+		log.Debug("propagate mark", logger.Attr(fn), slog.String("kind", v.kind.String()))
 		// Export kind
-		if bad {
+		switch v.kind {
+		case KindBad:
 			factWrapper.Export(fn, &isErrorHandler{Kind: KindBad})
-		} else {
-			factWrapper.Export(fn, &isErrorHandler{Kind: KindOK})
+		case KindUnknown:
+			factWrapper.Export(fn, &isErrorHandler{Kind: KindUnknown, Packages: v.packages})
+		case KindOK:
+			factWrapper.Export(fn, okFact(fn, factWrapper, cg))
 		}
 	}
 }
+
+// okFact returns KindOK fact with indices of fn's function-typed parameters whose result is returned.
+func okFact(fn *ssa.Function, factWrapper factImporter, cg *callgraph.CallGraph) *isErrorHandler {
+	fact := &isErrorHandler{Kind: KindOK}
+	info := cg.GetReturnInfo(fn)
+	if info == nil {
+		return fact
+	}
+	for _, rtn := range info.GetReturns() {
+		src := info.GetSource(rtn)
+		fact.Params = collectParams(fn, src, factWrapper, fact.Params)
+		fact.ParentParams = collectParentParams(src, factWrapper, fact.ParentParams)
+	}
+	slices.Sort(fact.Params)
+	fact.Params = slices.Compact(fact.Params)
+	slices.Sort(fact.ParentParams)
+	fact.ParentParams = slices.Compact(fact.ParentParams)
+	return fact
+}
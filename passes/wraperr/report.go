@@ -0,0 +1,227 @@
+package wraperr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/cloverrose/rpcguard/pkg/factutil"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph"
+)
+
+// categoryUnknown is category of diagnostics for errors that cannot be proven.
+const categoryUnknown = "unknown"
+
+const connectPath = "connectrpc.com/connect"
+
+func reportFunc(pass *analysis.Pass, fn *ssa.Function, fact *isErrorHandler) {
+	switch fact.Kind {
+	case KindBad:
+		pass.Reportf(fn.Pos(), reportMsg, fn.Name())
+	case KindUnknown:
+		pass.Report(analysis.Diagnostic{
+			Pos:      fn.Pos(),
+			Category: categoryUnknown,
+			Message:  fmt.Sprintf(unknownMsg, fn.Name(), strings.Join(fact.Packages, ",")),
+		})
+	case KindOK:
+	}
+}
+
+func reportReturn(
+	pass *analysis.Pass,
+	log *slog.Logger,
+	factWrapper *factutil.FactWrapper[*isErrorHandler],
+	info *callgraph.FuncInfo,
+	fn *ssa.Function,
+	rtn *ssa.Return,
+) {
+	src := info.GetSource(rtn)
+	v := checkSource(log, fn, src, nil, factWrapper)
+	switch v.kind {
+	case KindBad:
+		pass.Report(analysis.Diagnostic{
+			Pos:            rtn.Pos(),
+			Message:        fmt.Sprintf(reportMsg, fn.Name()),
+			Related:        relatedSources(src, factWrapper),
+			SuggestedFixes: wrapFixes(pass, rtn),
+		})
+	case KindUnknown:
+		if StrictUnknown {
+			pass.Report(analysis.Diagnostic{
+				Pos:      rtn.Pos(),
+				Category: categoryUnknown,
+				Message:  fmt.Sprintf(unknownMsg, fn.Name(), strings.Join(v.packages, ",")),
+				Related:  relatedSources(src, factWrapper),
+			})
+		}
+	case KindOK:
+	}
+}
+
+// relatedSources returns locations of functions returning the error which are not proven ok.
+func relatedSources(src *callgraph.Source, factWrapper factImporter) []analysis.RelatedInformation {
+	var related []analysis.RelatedInformation
+	for _, toFunc := range src.ToFuncs {
+		if isConnectNewError(toFunc) || !toFunc.Pos().IsValid() {
+			continue
+		}
+		v := checkToFunc(toFunc, factWrapper)
+		if v.kind == KindOK {
+			continue
+		}
+		related = append(related, analysis.RelatedInformation{
+			Pos:     toFunc.Pos(),
+			Message: fmt.Sprintf("error may come from %s (%s)", toFunc.String(), v.kind),
+		})
+	}
+	return related
+}
+
+// wrapFixes returns fix that wraps the returned error with connect.NewError.
+// It returns nil if the return statement or the import of connect is not found,
+// or the returned error is not provably non-nil (See isNonNilError), since wrapping nil turns success into failure.
+func wrapFixes(pass *analysis.Pass, rtn *ssa.Return) []analysis.SuggestedFix {
+	file := fileOf(pass, rtn.Pos())
+	if file == nil {
+		return nil
+	}
+	name, ok := connectImportName(file)
+	if !ok {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, rtn.Pos(), rtn.Pos())
+	if len(path) == 0 {
+		return nil
+	}
+	stmt, ok := path[0].(*ast.ReturnStmt)
+	if !ok || len(stmt.Results) != rtn.Parent().Signature.Results().Len() {
+		// E.g. return handle(ctx, req)
+		return nil
+	}
+	errExpr := stmt.Results[len(stmt.Results)-1]
+	if !isNonNilError(pass, path, errExpr) {
+		return nil
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Wrap error with connect.NewError",
+		TextEdits: []analysis.TextEdit{
+			{Pos: errExpr.Pos(), End: errExpr.Pos(), NewText: []byte(name + ".NewError(" + name + ".CodeInternal, ")},
+			{Pos: errExpr.End(), End: errExpr.End(), NewText: []byte(")")},
+		},
+	}}
+}
+
+// isNonNilError returns true if errExpr returned by the return statement path[0] is provably non-nil.
+// i.e. it is created by errors.New or fmt.Errorf, or it is a variable checked by the enclosing if statement
+// (e.g. if err != nil { return nil, err }) and not reassigned before the return.
+func isNonNilError(pass *analysis.Pass, path []ast.Node, errExpr ast.Expr) bool {
+	errExpr = ast.Unparen(errExpr)
+	if call, ok := errExpr.(*ast.CallExpr); ok {
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		return ok && slices.Contains(nonNilErrorFuncs, fn.FullName())
+	}
+	ident, ok := errExpr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	obj, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+	for i := 1; i < len(path); i++ {
+		ifStmt, ok := path[i].(*ast.IfStmt)
+		if !ok || path[i-1] != ifStmt.Body || !checksNonNil(pass, ifStmt.Cond, obj) {
+			continue
+		}
+		return !isAssigned(pass, ifStmt.Body, obj, errExpr.Pos())
+	}
+	return false
+}
+
+// nonNilErrorFuncs are functions which always return non-nil error.
+var nonNilErrorFuncs = []string{"errors.New", "fmt.Errorf"}
+
+// checksNonNil returns true if cond is true only when obj is not nil. E.g. err != nil, err != nil && ok
+func checksNonNil(pass *analysis.Pass, cond ast.Expr, obj *types.Var) bool {
+	expr, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch expr.Op {
+	case token.LAND:
+		return checksNonNil(pass, expr.X, obj) || checksNonNil(pass, expr.Y, obj)
+	case token.NEQ:
+		return isVarAndNil(pass, expr.X, expr.Y, obj) || isVarAndNil(pass, expr.Y, expr.X, obj)
+	default:
+		return false
+	}
+}
+
+// isVarAndNil returns true if x is obj and y is nil.
+func isVarAndNil(pass *analysis.Pass, x, y ast.Expr, obj *types.Var) bool {
+	ident, ok := ast.Unparen(x).(*ast.Ident)
+	return ok && pass.TypesInfo.Uses[ident] == obj && pass.TypesInfo.Types[y].IsNil()
+}
+
+// isAssigned returns true if obj is assigned or its address is taken in node before pos.
+func isAssigned(pass *analysis.Pass, node ast.Node, obj *types.Var, pos token.Pos) bool {
+	var assigned bool
+	ast.Inspect(node, func(n ast.Node) bool {
+		if assigned || n == nil || n.Pos() >= pos {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if ident, ok := ast.Unparen(lhs).(*ast.Ident); ok && pass.TypesInfo.ObjectOf(ident) == obj {
+					assigned = true
+				}
+			}
+		case *ast.UnaryExpr:
+			if ident, ok := ast.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND && pass.TypesInfo.Uses[ident] == obj {
+				assigned = true
+			}
+		}
+		return !assigned
+	})
+	return assigned
+}
+
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// connectImportName returns the name of connect package imported by file.
+func connectImportName(file *ast.File) (string, bool) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != connectPath {
+			continue
+		}
+		if spec.Name == nil {
+			return "connect", true
+		}
+		if spec.Name.Name == "_" || spec.Name.Name == "." {
+			return "", false
+		}
+		return spec.Name.Name, true
+	}
+	return "", false
+}
@@ -1,5 +1,12 @@
 package a06parameter
 
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
 type App struct{}
 
 type Message struct {
@@ -12,6 +19,75 @@ func (app *App) returnParamErr(err error) error { // want returnParamErr:"badFun
 }
 
 // returnParamFunc returns func its source is parameter.
-func (app *App) returnParamFunc(fn func() error) func() error { // want returnParamFunc:"badFunc"
+// It is judged by the func passed at each call site.
+func (app *App) returnParamFunc(fn func() error) func() error { // want returnParamFunc:"okFunc\\(param:1\\)"
 	return fn
 }
+
+// withRetry returns the result of fn.
+func withRetry(ctx context.Context, fn func() error) error { // want withRetry:"okFunc\\(param:1\\)"
+	for range 3 {
+		if err := fn(); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return connect.NewError(connect.CodeUnavailable, errors.New("retry exceeded"))
+}
+
+// retryTwice passes fn to withRetry, it returns the result of fn too.
+func retryTwice(ctx context.Context, fn func() error) error { // want retryTwice:"okFunc\\(param:1\\)"
+	return withRetry(ctx, func() error {
+		return withRetry(ctx, fn)
+	})
+}
+
+// retryHelper returns withRetry itself, the func passed to it is unknown.
+func retryHelper() func(context.Context, func() error) error { // want retryHelper:"badFunc"
+	return withRetry
+}
+
+func (app *App) connectErr() error { // want connectErr:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("connect error"))
+}
+
+func (app *App) RetryOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RetryOK:"okFunc"
+	if err := withRetry(ctx, func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("closure"))
+	}); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+func (app *App) RetryMethodOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RetryMethodOK:"okFunc"
+	return nil, retryTwice(ctx, app.connectErr)
+}
+
+func (app *App) RetryBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RetryBad:"badFunc" ".*RPC method RetryBad returns error.*"
+	if err := withRetry(ctx, func() error {
+		return errors.New("closure")
+	}); err != nil {
+		return nil, err // want ".*RPC method RetryBad returns error.*"
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+func (app *App) RetryTwiceBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RetryTwiceBad:"badFunc" ".*RPC method RetryTwiceBad returns error.*"
+	return nil, retryTwice(ctx, func() error { // want ".*RPC method RetryTwiceBad returns error.*"
+		return app.returnParamErr(errors.New("closure"))
+	})
+}
+
+func (app *App) ReturnParamFuncOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ReturnParamFuncOK:"okFunc"
+	return nil, app.returnParamFunc(app.connectErr)()
+}
+
+func (app *App) ReturnParamFuncBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ReturnParamFuncBad:"badFunc" ".*RPC method ReturnParamFuncBad returns error.*"
+	return nil, app.returnParamFunc(func() error { // want ".*RPC method ReturnParamFuncBad returns error.*"
+		return errors.New("closure")
+	})()
+}
+
+func (app *App) RetryHelperBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RetryHelperBad:"badFunc" ".*RPC method RetryHelperBad returns error.*"
+	return nil, retryHelper()(ctx, app.connectErr) // want ".*RPC method RetryHelperBad returns error.*"
+}
@@ -1,10 +1,20 @@
 package a
 
 import (
+	"context"
+
+	"connectrpc.com/connect"
+
 	"a/a08import/excludedpkg"
 	"a/a08import/includedpkg"
 )
 
+type App struct{}
+
+type Message struct {
+	text string
+}
+
 func CallIncludedOKFunc() error { // want CallIncludedOKFunc:"okFunc"
 	return includedpkg.OKFunc()
 }
@@ -13,6 +23,36 @@ func CallIncludedBadFunc() error { // want CallIncludedBadFunc:"badFunc"
 	return includedpkg.BadFunc()
 }
 
-func CallExcludedOKFunc() error { // want CallExcludedOKFunc:"badFunc"
+// CallExcludedOKFunc calls func in the same module, but the package is not in IncludePackages.
+func CallExcludedOKFunc() error { // want CallExcludedOKFunc:"unknownFunc\\(pkg:a/a08import/excludedpkg\\)"
 	return excludedpkg.OKFunc()
 }
+
+// callBoth can't be proven, but it is bad anyway.
+func callBoth(included bool) error { // want callBoth:"badFunc"
+	if included {
+		return includedpkg.BadFunc()
+	}
+	return CallExcludedOKFunc()
+}
+
+func (app *App) ExcludedUnknown(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ExcludedUnknown:"unknownFunc\\(pkg:a/a08import/excludedpkg\\)" ".*RPC method ExcludedUnknown returns error that cannot be proven wrapped with connect.NewError: callee pkg a/a08import/excludedpkg is not in IncludePackages"
+	if err := CallExcludedOKFunc(); err != nil {
+		return nil, err // want ".*RPC method ExcludedUnknown returns error that cannot be proven.*"
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+func (app *App) ExcludedBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ExcludedBad:"badFunc" ".*RPC method ExcludedBad returns error that is not wrapped.*"
+	if err := CallExcludedOKFunc(); err != nil {
+		return nil, err // want ".*RPC method ExcludedBad returns error that cannot be proven.*"
+	}
+	if err := includedpkg.BadFunc(); err != nil {
+		return nil, err // want ".*RPC method ExcludedBad returns error that is not wrapped.*"
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+func (app *App) IncludedOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want IncludedOK:"okFunc"
+	return nil, CallIncludedOKFunc()
+}
@@ -1,6 +1,7 @@
 package a10defer
 
 import (
+	"context"
 	"errors"
 
 	"connectrpc.com/connect"
@@ -26,12 +27,137 @@ func DeferFuncBad() (x string, err error) { // want DeferFuncBad:"badFunc"
 	return "ok", nil
 }
 
-// DeferFuncBadConnectError has a defer block.
-// err is assigned with connect error but it is treated as badFunc.
-func DeferFuncBadConnectError() (x string, err error) { // want DeferFuncBadConnectError:"badFunc"
+// DeferFuncOKConnectError has a defer block.
+// err is assigned with connect error in defer.
+func DeferFuncOKConnectError() (x string, err error) { // want DeferFuncOKConnectError:"okFunc"
 	defer func() {
 		x = "set in defer"
 		err = connect.NewError(connect.CodeInternal, errors.New("set in defer"))
 	}()
 	return "ok", nil
 }
+
+// AccumulateOK updates err through closure, all stored errors are connect error.
+func AccumulateOK(n int) error { // want AccumulateOK:"okFunc"
+	var err error
+	update := func(i int) {
+		if i%2 == 0 {
+			err = connect.NewError(connect.CodeInternal, errors.New("even"))
+		}
+	}
+	for i := range n {
+		update(i)
+	}
+	return err
+}
+
+// AccumulateBad updates err through closure, one of stored errors is not connect error.
+func AccumulateBad(n int) error { // want AccumulateBad:"badFunc"
+	var err error
+	update := func(i int) {
+		if i%2 == 0 {
+			err = connect.NewError(connect.CodeInternal, errors.New("even"))
+		} else {
+			err = errors.New("odd")
+		}
+	}
+	for i := range n {
+		update(i)
+	}
+	return err
+}
+
+// EscapeBad passes pointer of err to other func, stores can't be tracked.
+func EscapeBad() (err error) { // want EscapeBad:"badFunc"
+	setErr(&err)
+	return err
+}
+
+func setErr(p *error) {
+	*p = connect.NewError(connect.CodeInternal, errors.New("set"))
+}
+
+type App struct{}
+
+type Message struct{}
+
+// DeferWrapOK wraps named result err in defer on non-nil path, every return is OK.
+func (app *App) DeferWrapOK(_ context.Context, req *connect.Request[Message]) (res *connect.Response[Message], err error) { // want DeferWrapOK:"okFunc"
+	defer func() {
+		if err != nil {
+			err = connect.NewError(toCode(err), err)
+		}
+	}()
+	if req.Msg == nil {
+		return nil, errors.New("msg is nil")
+	}
+	if err := ReturnUnwrapError(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+// DeferWrapUnconditionalOK wraps named result err in defer unconditionally.
+func DeferWrapUnconditionalOK() (err error) { // want DeferWrapUnconditionalOK:"okFunc"
+	defer func() {
+		err = wrap(err)
+	}()
+	return errors.New("unwrap err")
+}
+
+// DeferWrapPartialBad wraps named result err in defer only for some errors.
+// Every return loads named result err after rundefers, so every return is reported.
+func (app *App) DeferWrapPartialBad(_ context.Context, req *connect.Request[Message]) (res *connect.Response[Message], err error) { // want DeferWrapPartialBad:"badFunc" ".*RPC method DeferWrapPartialBad returns error.*"
+	defer func() {
+		if err != nil && req.Msg != nil {
+			err = connect.NewError(toCode(err), err)
+		}
+	}()
+	if err := ReturnUnwrapError(); err != nil {
+		return nil, err // want ".*RPC method DeferWrapPartialBad returns error.*"
+	}
+	return connect.NewResponse(&Message{}), nil // want ".*RPC method DeferWrapPartialBad returns error.*"
+}
+
+// DeferWrapAfterReturnBad registers defer after early return.
+func DeferWrapAfterReturnBad(flag bool) (err error) { // want DeferWrapAfterReturnBad:"badFunc"
+	if flag {
+		return errors.New("before defer")
+	}
+	defer func() {
+		if err != nil {
+			err = connect.NewError(toCode(err), err)
+		}
+	}()
+	return ReturnUnwrapError()
+}
+
+// DeferLocalVarBad wraps local variable in defer, but returned value is read before defer runs.
+func DeferLocalVarBad() error { // want DeferLocalVarBad:"badFunc"
+	var err error
+	defer func() {
+		if err != nil {
+			err = connect.NewError(toCode(err), err)
+		}
+	}()
+	err = ReturnUnwrapError()
+	return err
+}
+
+func ReturnUnwrapError() error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
+
+func wrap(err error) error { // want wrap:"okFunc"
+	if err == nil {
+		return nil
+	}
+	return connect.NewError(toCode(err), err)
+}
+
+func toCode(err error) connect.Code {
+	if errors.Is(err, context.Canceled) {
+		return connect.CodeCanceled
+	}
+	return connect.CodeInternal
+}
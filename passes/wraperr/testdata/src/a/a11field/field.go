@@ -0,0 +1,108 @@
+package a11field
+
+// This file contains struct fields and captured variables which hold funcs or errors.
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type Message struct {
+	text string
+}
+
+type App struct {
+	okHandler  func(ctx context.Context) error
+	badHandler func(ctx context.Context) error
+	escaped    func(ctx context.Context) error
+	Exported   func(ctx context.Context) error
+	lastErr    error
+}
+
+func NewApp() *App {
+	app := &App{
+		okHandler:  ReturnWrapError,
+		badHandler: ReturnWrapError,
+		Exported:   ReturnWrapError,
+	}
+	app.badHandler = ReturnUnwrapError
+	setHandler(&app.escaped)
+	app.lastErr = connect.NewError(connect.CodeInternal, errors.New("last"))
+	return app
+}
+
+func setHandler(p *func(ctx context.Context) error) {
+	*p = ReturnWrapError
+}
+
+// CallOKField calls func stored in field, all stored funcs are ok.
+func (app *App) CallOKField(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallOKField:"okFunc"
+	if err := app.okHandler(ctx); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"CallOKField"}), nil
+}
+
+// CallBadField calls func stored in field, one of stored funcs is bad.
+func (app *App) CallBadField(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallBadField:"badFunc" ".*RPC method CallBadField returns error.*"
+	if err := app.badHandler(ctx); err != nil {
+		return nil, err // want ".*RPC method CallBadField returns error.*"
+	}
+	return connect.NewResponse(&Message{"CallBadField"}), nil
+}
+
+// CallEscapedField calls func stored in field whose address escapes, stores can't be tracked.
+func (app *App) CallEscapedField(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallEscapedField:"badFunc" ".*RPC method CallEscapedField returns error.*"
+	if err := app.escaped(ctx); err != nil {
+		return nil, err // want ".*RPC method CallEscapedField returns error.*"
+	}
+	return connect.NewResponse(&Message{"CallEscapedField"}), nil
+}
+
+// CallExportedField calls func stored in exported field, other packages can store to it.
+func (app *App) CallExportedField(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallExportedField:"badFunc" ".*RPC method CallExportedField returns error.*"
+	if err := app.Exported(ctx); err != nil {
+		return nil, err // want ".*RPC method CallExportedField returns error.*"
+	}
+	return connect.NewResponse(&Message{"CallExportedField"}), nil
+}
+
+// ReturnErrorField returns error stored in field.
+func (app *App) ReturnErrorField() error { // want ReturnErrorField:"okFunc"
+	return app.lastErr
+}
+
+// CallCapturedFunc calls captured func in closure.
+func (app *App) CallCapturedFunc(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallCapturedFunc:"okFunc"
+	handler := ReturnWrapError
+	call := func() error {
+		return handler(ctx)
+	}
+	if err := call(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"CallCapturedFunc"}), nil
+}
+
+// CallReassignedCapturedFunc calls captured func that is reassigned in closure.
+func (app *App) CallReassignedCapturedFunc(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallReassignedCapturedFunc:"badFunc" ".*RPC method CallReassignedCapturedFunc returns error.*"
+	handler := ReturnWrapError
+	reset := func() {
+		handler = ReturnUnwrapError
+	}
+	reset()
+	if err := handler(ctx); err != nil {
+		return nil, err // want ".*RPC method CallReassignedCapturedFunc returns error.*"
+	}
+	return connect.NewResponse(&Message{"CallReassignedCapturedFunc"}), nil
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
@@ -0,0 +1,38 @@
+package a12builtin
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct{}
+
+// recoverErr returns recovered value, the value passed to panic can't be tracked.
+func recoverErr() error { // want recoverErr:"badFunc"
+	return recover().(error)
+}
+
+func (app *App) CallRecoverErr(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallRecoverErr:"badFunc" ".*RPC method CallRecoverErr returns error.*"
+	return nil, recoverErr() // want ".*RPC method CallRecoverErr returns error.*"
+}
+
+func (app *App) RecoverErr(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want RecoverErr:"badFunc" ".*RPC method RecoverErr returns error.*"
+	return nil, recover().(error) // want ".*RPC method RecoverErr returns error.*"
+}
+
+func (app *App) ReturnNil(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ReturnNil:"okFunc"
+	return connect.NewResponse(&Message{}), nil
+}
+
+// RecoverToErr returns recovered error through named result, the value passed to panic can't be tracked.
+func (app *App) RecoverToErr(_ context.Context, _ *connect.Request[Message]) (_ *connect.Response[Message], err error) { // want RecoverToErr:"badFunc" ".*RPC method RecoverToErr returns error.*"
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	return connect.NewResponse(&Message{}), nil // want ".*RPC method RecoverToErr returns error.*"
+}
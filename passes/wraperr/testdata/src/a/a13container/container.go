@@ -0,0 +1,141 @@
+package a13container
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+func connectErr() error { // want connectErr:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("connect error"))
+}
+
+func unwrapErr() error { // want unwrapErr:"badFunc"
+	return errors.New("unwrap error")
+}
+
+// ChanOK receives error sent in goroutine.
+func ChanOK() error { // want ChanOK:"okFunc"
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- connectErr()
+	}()
+	return <-errCh
+}
+
+// ChanBad receives error sent in goroutine, one of sent errors is not connect error.
+func ChanBad(flag bool) error { // want ChanBad:"badFunc"
+	errCh := make(chan error, 2)
+	go func() {
+		if flag {
+			errCh <- unwrapErr()
+			return
+		}
+		errCh <- connectErr()
+	}()
+	return <-errCh
+}
+
+// ChanRangeOK ranges over receive only channel.
+func ChanRangeOK() error { // want ChanRangeOK:"okFunc"
+	errCh := make(chan error)
+	go func() {
+		defer close(errCh)
+		errCh <- connectErr()
+	}()
+	var recvCh <-chan error = errCh
+	for err := range recvCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelectOK receives error in select.
+func SelectOK(ctx context.Context) error { // want SelectOK:"okFunc"
+	errCh := make(chan error, 1)
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case errCh <- connectErr():
+		case <-doneCh:
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return connect.NewError(connect.CodeCanceled, ctx.Err())
+	case err := <-errCh:
+		return err
+	}
+}
+
+// SelectBad receives error in select, one of sent errors is not connect error.
+func SelectBad(ctx context.Context) error { // want SelectBad:"badFunc"
+	errCh := make(chan error, 1)
+	resultCh := make(chan error, 1)
+	errCh <- connectErr()
+	resultCh <- unwrapErr()
+	select {
+	case err := <-errCh:
+		return err
+	case err, ok := <-resultCh:
+		if !ok {
+			return nil
+		}
+		return err
+	}
+}
+
+// ChanEscapeBad passes channel to other function, sends can't be tracked.
+func ChanEscapeBad() error { // want ChanEscapeBad:"badFunc"
+	errCh := make(chan error, 1)
+	send(errCh)
+	return <-errCh
+}
+
+func send(errCh chan<- error) {
+	errCh <- connectErr()
+}
+
+// MapOK reads error from map, all stored errors are connect error.
+func MapOK(key string) error { // want MapOK:"okFunc"
+	errs := map[string]error{
+		"a": connectErr(),
+	}
+	errs["b"] = connect.NewError(connect.CodeNotFound, errors.New("not found"))
+	if err, ok := errs[key]; ok {
+		return err
+	}
+	return errs["a"]
+}
+
+// MapBad reads error from map, one of stored errors is not connect error.
+func MapBad(key string) error { // want MapBad:"badFunc"
+	errs := make(map[string]error)
+	errs["a"] = connectErr()
+	update := func() {
+		errs["b"] = unwrapErr()
+	}
+	update()
+	return errs[key]
+}
+
+// MapRangeOK ranges over map.
+func MapRangeOK() error { // want MapRangeOK:"okFunc"
+	errs := map[string]error{
+		"a": connectErr(),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapParamBad reads error from map given by caller, stores can't be tracked.
+func MapParamBad(errs map[string]error) error { // want MapParamBad:"badFunc"
+	return errs["a"]
+}
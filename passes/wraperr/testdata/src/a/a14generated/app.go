@@ -0,0 +1,22 @@
+package a14generated
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// CallGeneratedOK is judged by the fact of the func in generated file.
+func (app *App) CallGeneratedOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallGeneratedOK:"okFunc"
+	return nil, generatedOK()
+}
+
+func (app *App) CallGeneratedBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CallGeneratedBad:"badFunc" ".*RPC method CallGeneratedBad returns error.*"
+	return nil, generatedBad() // want ".*RPC method CallGeneratedBad returns error.*"
+}
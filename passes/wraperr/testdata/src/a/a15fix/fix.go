@@ -0,0 +1,49 @@
+package a15fix
+
+import (
+	"context"
+	"errors"
+
+	connectrpc "connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+func newErr() error { // want newErr:"badFunc"
+	return errors.New("bad")
+}
+
+func (app *App) Bad(_ context.Context, _ *connectrpc.Request[Message]) (*connectrpc.Response[Message], error) { // want Bad:"badFunc"
+	if err := newErr(); err != nil {
+		return nil, err // want ".*RPC method Bad returns error.*"
+	}
+	return nil, errors.New("bad") // want ".*RPC method Bad returns error.*"
+}
+
+func handle() (*connectrpc.Response[Message], error) { // want handle:"badFunc"
+	return nil, errors.New("bad")
+}
+
+// BadTuple returns the result of call as is, wrapping it can't be suggested.
+func (app *App) BadTuple(_ context.Context, _ *connectrpc.Request[Message]) (*connectrpc.Response[Message], error) { // want BadTuple:"badFunc"
+	return handle() // want ".*RPC method BadTuple returns error.*"
+}
+
+// BadMaybeNil returns err which may be nil, wrapping it would turn success into failure.
+func (app *App) BadMaybeNil(_ context.Context, _ *connectrpc.Request[Message]) (*connectrpc.Response[Message], error) { // want BadMaybeNil:"badFunc"
+	resp, err := handle()
+	return resp, err // want ".*RPC method BadMaybeNil returns error.*"
+}
+
+// BadReassigned reassigns err in the block, it may be nil.
+func (app *App) BadReassigned(_ context.Context, _ *connectrpc.Request[Message]) (*connectrpc.Response[Message], error) { // want BadReassigned:"badFunc"
+	if err := newErr(); err != nil {
+		_, err = handle()
+		return nil, err // want ".*RPC method BadReassigned returns error.*"
+	}
+	return nil, nil
+}
@@ -0,0 +1,61 @@
+package agg01errors
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// JoinOK joins only wrap errors.
+func (app *App) JoinOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want JoinOK:"okFunc"
+	err1 := ReturnWrapError(ctx)
+	err2 := ReturnWrapError(ctx)
+	if err := errors.Join(err1, err2, nil); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"JoinOK"}), nil
+}
+
+// JoinBad joins wrap error and unwrap error.
+func (app *App) JoinBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want JoinBad:"badFunc" ".*RPC method JoinBad returns error.*"
+	err1 := ReturnWrapError(ctx)
+	err2 := ReturnUnwrapError(ctx)
+	if err := errors.Join(err1, err2); err != nil {
+		return nil, err // want ".*RPC method JoinBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"JoinBad"}), nil
+}
+
+// JoinNested joins joined wrap errors.
+func (app *App) JoinNested(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want JoinNested:"okFunc"
+	err := errors.Join(errors.Join(ReturnWrapError(ctx)), ReturnWrapError(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"JoinNested"}), nil
+}
+
+// JoinSlice joins slice of errors, elements can't be analyzed.
+func (app *App) JoinSlice(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want JoinSlice:"badFunc" ".*RPC method JoinSlice returns error.*"
+	var errs []error
+	errs = append(errs, ReturnWrapError(ctx))
+	if err := errors.Join(errs...); err != nil {
+		return nil, err // want ".*RPC method JoinSlice returns error.*"
+	}
+	return connect.NewResponse(&Message{"JoinSlice"}), nil
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
@@ -0,0 +1,61 @@
+package agg02multierr
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"go.uber.org/multierr"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// AppendOK appends only wrap errors.
+func (app *App) AppendOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want AppendOK:"okFunc"
+	var err error
+	err = multierr.Append(err, ReturnWrapError(ctx))
+	err = multierr.Append(err, ReturnWrapError(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"AppendOK"}), nil
+}
+
+// AppendBad appends wrap error and unwrap error.
+func (app *App) AppendBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want AppendBad:"badFunc" ".*RPC method AppendBad returns error.*"
+	var err error
+	err = multierr.Append(err, ReturnWrapError(ctx))
+	err = multierr.Append(err, ReturnUnwrapError(ctx))
+	if err != nil {
+		return nil, err // want ".*RPC method AppendBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"AppendBad"}), nil
+}
+
+// CombineOK combines only wrap errors.
+func (app *App) CombineOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CombineOK:"okFunc"
+	if err := multierr.Combine(ReturnWrapError(ctx), ReturnWrapError(ctx)); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"CombineOK"}), nil
+}
+
+// CombineBad combines wrap error and unwrap error.
+func (app *App) CombineBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want CombineBad:"badFunc" ".*RPC method CombineBad returns error.*"
+	if err := multierr.Combine(ReturnWrapError(ctx), ReturnUnwrapError(ctx)); err != nil {
+		return nil, err // want ".*RPC method CombineBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"CombineBad"}), nil
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
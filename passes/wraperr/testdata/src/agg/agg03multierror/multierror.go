@@ -0,0 +1,62 @@
+package agg03multierror
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/hashicorp/go-multierror"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// ErrorOrNilOK appends only wrap errors in loop.
+func (app *App) ErrorOrNilOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorOrNilOK:"okFunc"
+	var result *multierror.Error
+	for range 3 {
+		if err := ReturnWrapError(ctx); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"ErrorOrNilOK"}), nil
+}
+
+// ErrorOrNilBad appends wrap error and unwrap error.
+func (app *App) ErrorOrNilBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorOrNilBad:"badFunc" ".*RPC method ErrorOrNilBad returns error.*"
+	var result *multierror.Error
+	if err := ReturnWrapError(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+	if err := ReturnUnwrapError(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err // want ".*RPC method ErrorOrNilBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"ErrorOrNilBad"}), nil
+}
+
+// AppendOK returns appended wrap errors as error.
+func (app *App) AppendOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want AppendOK:"okFunc"
+	var err error
+	err = multierror.Append(err, ReturnWrapError(ctx), ReturnWrapError(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"AppendOK"}), nil
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
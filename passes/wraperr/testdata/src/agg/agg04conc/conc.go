@@ -0,0 +1,67 @@
+package agg04conc
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/sourcegraph/conc/pool"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// ErrorPoolOK calls OK closure in p.Go and returns p.Wait error
+func (app *App) ErrorPoolOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorPoolOK:"okFunc"
+	p := pool.New().WithMaxGoroutines(2).WithErrors()
+	p.Go(func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+	})
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"ErrorPoolOK"}), nil
+}
+
+// ErrorPoolBad calls bad closure in p.Go and returns p.Wait error
+func (app *App) ErrorPoolBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorPoolBad:"badFunc" ".*RPC method ErrorPoolBad returns error.*"
+	p := pool.New().WithErrors()
+	p.Go(func() error {
+		return errors.New("unwrap err")
+	})
+	if err := p.Wait(); err != nil {
+		return nil, err // want ".*RPC method ErrorPoolBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"ErrorPoolBad"}), nil
+}
+
+// ContextPoolOK calls OK method in p.Go and returns p.Wait error
+func (app *App) ContextPoolOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ContextPoolOK:"okFunc"
+	p := pool.New().WithContext(ctx)
+	p.Go(app.ReturnWrapError)
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"ContextPoolOK"}), nil
+}
+
+// ContextPoolBad calls bad method in p.Go and returns p.Wait error
+func (app *App) ContextPoolBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ContextPoolBad:"badFunc" ".*RPC method ContextPoolBad returns error.*"
+	p := pool.New().WithContext(ctx)
+	p.Go(app.ReturnUnwrapError)
+	if err := p.Wait(); err != nil {
+		return nil, err // want ".*RPC method ContextPoolBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"ContextPoolBad"}), nil
+}
+
+func (app *App) ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func (app *App) ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
@@ -0,0 +1,70 @@
+package agg05fmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// ErrorfWrapOK wraps ok error with %w.
+func (app *App) ErrorfWrapOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfWrapOK:"okFunc"
+	if err := ReturnWrapError(ctx); err != nil {
+		return nil, fmt.Errorf("failed %d times: %w", 1, err)
+	}
+	return connect.NewResponse(&Message{"ErrorfWrapOK"}), nil
+}
+
+// ErrorfWrapBad wraps bad error with %w.
+func (app *App) ErrorfWrapBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfWrapBad:"badFunc" ".*RPC method ErrorfWrapBad returns error.*"
+	if err := ReturnUnwrapError(ctx); err != nil {
+		return nil, fmt.Errorf("failed: %w", err) // want ".*RPC method ErrorfWrapBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"ErrorfWrapBad"}), nil
+}
+
+// ErrorfNoWrap formats ok error with %v, it loses the code.
+func (app *App) ErrorfNoWrap(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfNoWrap:"badFunc" ".*RPC method ErrorfNoWrap returns error.*"
+	if err := ReturnWrapError(ctx); err != nil {
+		return nil, fmt.Errorf("failed: %v", err) // want ".*RPC method ErrorfNoWrap returns error.*"
+	}
+	return connect.NewResponse(&Message{"ErrorfNoWrap"}), nil
+}
+
+// ErrorfMultiWrap wraps ok error and bad error with %w.
+func (app *App) ErrorfMultiWrap(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfMultiWrap:"badFunc" ".*RPC method ErrorfMultiWrap returns error.*"
+	err1 := ReturnWrapError(ctx)
+	err2 := ReturnUnwrapError(ctx)
+	return nil, fmt.Errorf("%w, %w", err1, err2) // want ".*RPC method ErrorfMultiWrap returns error.*"
+}
+
+// ErrorfIndexedWrap wraps ok error with %[2]w and formats bad error with %[1]v.
+func (app *App) ErrorfIndexedWrap(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfIndexedWrap:"okFunc"
+	err1 := ReturnUnwrapError(ctx)
+	err2 := ReturnWrapError(ctx)
+	return nil, fmt.Errorf("%[2]w: %[1]v", err1, err2)
+}
+
+// ErrorfWrapNil wraps error which may be nil with %w, fmt.Errorf returns error without code for nil.
+func (app *App) ErrorfWrapNil(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ErrorfWrapNil:"badFunc" ".*RPC method ErrorfWrapNil returns error.*"
+	var err error
+	if ctx.Err() == nil {
+		err = ReturnWrapError(ctx)
+	}
+	return nil, fmt.Errorf("failed: %w", err) // want ".*RPC method ErrorfWrapNil returns error.*"
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
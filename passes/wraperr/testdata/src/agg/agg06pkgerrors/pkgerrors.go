@@ -0,0 +1,55 @@
+package agg06pkgerrors
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+)
+
+type App struct{}
+
+type Message struct {
+	text string
+}
+
+// WithStackOK wraps ok error with errors.WithStack.
+func (app *App) WithStackOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want WithStackOK:"okFunc"
+	if err := ReturnWrapError(ctx); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return connect.NewResponse(&Message{"WithStackOK"}), nil
+}
+
+// WrapfOK wraps ok error with errors.Wrapf.
+func (app *App) WrapfOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want WrapfOK:"okFunc"
+	if err := ReturnWrapError(ctx); err != nil {
+		return nil, errors.Wrapf(err, "failed %d times", 1)
+	}
+	return connect.NewResponse(&Message{"WrapfOK"}), nil
+}
+
+// WrapBad wraps bad error with errors.Wrap.
+func (app *App) WrapBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want WrapBad:"badFunc" ".*RPC method WrapBad returns error.*"
+	if err := ReturnUnwrapError(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed") // want ".*RPC method WrapBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"WrapBad"}), nil
+}
+
+// WrapNilOK wraps error which may be nil with errors.Wrap, it returns nil for nil.
+func (app *App) WrapNilOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want WrapNilOK:"okFunc"
+	var err error
+	if ctx.Err() == nil {
+		err = ReturnWrapError(ctx)
+	}
+	return nil, errors.Wrap(err, "failed")
+}
+
+func ReturnWrapError(_ context.Context) error { // want ReturnWrapError:"okFunc"
+	return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+}
+
+func ReturnUnwrapError(_ context.Context) error { // want ReturnUnwrapError:"badFunc"
+	return errors.New("unwrap err")
+}
@@ -0,0 +1,16 @@
+module agg
+
+go 1.24.6
+
+require (
+	connectrpc.com/connect v1.18.1
+	github.com/hashicorp/go-multierror v1.1.1
+	github.com/pkg/errors v0.9.1
+	github.com/sourcegraph/conc v0.3.0
+	go.uber.org/multierr v1.11.0
+)
+
+require (
+	github.com/hashicorp/errwrap v1.0.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
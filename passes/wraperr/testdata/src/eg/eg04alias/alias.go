@@ -0,0 +1,185 @@
+package eg04alias
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"golang.org/x/sync/errgroup"
+)
+
+type App struct {
+	eg    *errgroup.Group
+	group errgroup.Group
+}
+
+type Message struct {
+	text string
+}
+
+//----------------------------------------------------------------------------------------------------------------------
+
+// TryGoOK calls OK closure in eg.TryGo and returns eg.Wait error
+func (app *App) TryGoOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want TryGoOK:"okFunc"
+	eg, _ := errgroup.WithContext(context.Background())
+	eg.SetLimit(2)
+
+	eg.TryGo(func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"TryGoOK"}), nil
+}
+
+// TryGoBad calls bad closure in eg.TryGo and returns eg.Wait error
+func (app *App) TryGoBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want TryGoBad:"badFunc" ".*RPC method TryGoBad returns error.*"
+	eg, _ := errgroup.WithContext(context.Background())
+	eg.SetLimit(2)
+
+	if !eg.TryGo(func() error {
+		return errors.New("unwrap err")
+	}) {
+		return connect.NewResponse(&Message{"TryGoBad"}), nil
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method TryGoBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"TryGoBad"}), nil
+}
+
+//----------------------------------------------------------------------------------------------------------------------
+
+// HelperOK passes eg to helper that calls eg.Go with OK closure.
+func (app *App) HelperOK(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want HelperOK:"okFunc"
+	eg, _ := errgroup.WithContext(context.Background())
+	addOKTasks(eg)
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"HelperOK"}), nil
+}
+
+// HelperBad passes eg to helper that calls eg.Go with bad closure.
+func (app *App) HelperBad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want HelperBad:"badFunc" ".*RPC method HelperBad returns error.*"
+	eg, _ := errgroup.WithContext(context.Background())
+	addBadTasks(eg)
+	if err := eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method HelperBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"HelperBad"}), nil
+}
+
+func addOKTasks(eg *errgroup.Group) {
+	eg.Go(func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+	})
+}
+
+func addBadTasks(eg *errgroup.Group) {
+	addNestedBadTasks(eg)
+}
+
+func addNestedBadTasks(eg *errgroup.Group) {
+	eg.Go(func() error {
+		return errors.New("unwrap err")
+	})
+}
+
+//----------------------------------------------------------------------------------------------------------------------
+
+// LocalVar uses errgroup.Group variable and calls eg.Go via pointer.
+func (app *App) LocalVar(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want LocalVar:"badFunc" ".*RPC method LocalVar returns error.*"
+	var eg errgroup.Group
+	p := &eg
+	p.Go(func() error {
+		return errors.New("unwrap err")
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method LocalVar returns error.*"
+	}
+	return connect.NewResponse(&Message{"LocalVar"}), nil
+}
+
+// Closure calls eg.Go in closure that captures eg.
+func (app *App) Closure(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want Closure:"badFunc" ".*RPC method Closure returns error.*"
+	eg, _ := errgroup.WithContext(context.Background())
+	add := func() {
+		eg.Go(func() error {
+			return errors.New("unwrap err")
+		})
+	}
+	add()
+	if err := eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method Closure returns error.*"
+	}
+	return connect.NewResponse(&Message{"Closure"}), nil
+}
+
+//----------------------------------------------------------------------------------------------------------------------
+
+// ReturnedOK uses eg returned by helper that calls eg.Go with OK closure.
+func (app *App) ReturnedOK(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ReturnedOK:"okFunc"
+	eg, _ := newOKGroup(ctx)
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"ReturnedOK"}), nil
+}
+
+// ReturnedBad uses eg returned by helper and calls eg.Go with bad closure.
+func (app *App) ReturnedBad(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want ReturnedBad:"badFunc" ".*RPC method ReturnedBad returns error.*"
+	eg := newBadGroup(ctx)
+	if err := eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method ReturnedBad returns error.*"
+	}
+	return connect.NewResponse(&Message{"ReturnedBad"}), nil
+}
+
+func newOKGroup(ctx context.Context) (*errgroup.Group, context.Context) {
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+	})
+	return eg, ctx
+}
+
+func newBadGroup(ctx context.Context) *errgroup.Group {
+	eg, _ := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return errors.New("unwrap err")
+	})
+	return eg
+}
+
+//----------------------------------------------------------------------------------------------------------------------
+
+// FieldPointer calls eg.Go in other method via struct field.
+func (app *App) FieldPointer(ctx context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want FieldPointer:"badFunc" ".*RPC method FieldPointer returns error.*"
+	app.eg, _ = errgroup.WithContext(ctx)
+	app.start()
+	if err := app.eg.Wait(); err != nil {
+		return nil, err // want ".*RPC method FieldPointer returns error.*"
+	}
+	return connect.NewResponse(&Message{"FieldPointer"}), nil
+}
+
+func (app *App) start() {
+	app.eg.Go(func() error {
+		return errors.New("unwrap err")
+	})
+}
+
+// FieldValue calls eg.Go via errgroup.Group struct field.
+func (app *App) FieldValue(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want FieldValue:"okFunc"
+	app.group.Go(func() error {
+		return connect.NewError(connect.CodeInternal, errors.New("wrap err"))
+	})
+	if err := app.group.Wait(); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&Message{"FieldValue"}), nil
+}
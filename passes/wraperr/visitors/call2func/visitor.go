@@ -1,7 +1,7 @@
 package call2func
 
 import (
-	"errors"
+	"slices"
 
 	"golang.org/x/tools/go/ssa"
 
@@ -9,32 +9,46 @@ import (
 )
 
 type visitorPlugin struct {
-	ret *ssa.Function
+	ret []*ssa.Function
 }
 
 func (p *visitorPlugin) VisitFunction(val *ssa.Function) error {
-	if p.ret != nil {
-		return errors.New("unexpected ret is not nil")
+	if !slices.Contains(p.ret, val) {
+		p.ret = append(p.ret, val)
 	}
-	p.ret = val
 	return nil
 }
 
 func (p *visitorPlugin) createOptions() []ssawalk.Option {
 	return []ssawalk.Option{
 		ssawalk.WithVisitFunction(p.VisitFunction),
+		// recovered value is not able to analyze like other complex values.
+		ssawalk.WithVisitRecover(func(*ssa.Builtin) error { return nil }),
 	}
 }
 
+// GetFuncFromCall returns the func that is the source of value.
+// It returns nil if the source is not able to analyze or there are multiple sources.
 func GetFuncFromCall(value ssa.Value) (*ssa.Function, error) {
+	funcs, err := GetFuncsFromCall(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(funcs) != 1 {
+		// srcFnc calls func that is not able to analyze like func in parameter,
+		// or func stored in field that has multiple sources.
+		return nil, nil
+	}
+	return funcs[0], nil
+}
+
+// GetFuncsFromCall returns all funcs that are source of value.
+// E.g. value is a field and multiple funcs are stored to it.
+func GetFuncsFromCall(value ssa.Value) ([]*ssa.Function, error) {
 	plugin := &visitorPlugin{}
 	visitor := ssawalk.NewDefaultVisitorWith(plugin.createOptions()...)
 	if err := ssawalk.Walk(visitor, value); err != nil {
 		return nil, err
 	}
-	if plugin.ret == nil {
-		// srcFnc calls func that is not able to analyze like func in parameter.
-		return nil, nil
-	}
 	return plugin.ret, nil
 }
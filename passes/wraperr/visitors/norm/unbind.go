@@ -1,10 +1,11 @@
 package norm
 
 import (
-	"fmt"
 	"strings"
 
 	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
 )
 
 // This file contains bounded method wrapper support.
@@ -36,16 +37,16 @@ func unbind(fn *ssa.Function) (*ssa.Function, error) {
 // unwrap returns a function that is wrapped by wrapperFunc.
 func unwrap(wrapperFunc *ssa.Function) (*ssa.Function, error) {
 	if len(wrapperFunc.Blocks) != 1 {
-		panic(fmt.Sprintf("unexpected wrapper func (len(Blocks) != 1) %v", wrapperFunc.Name()))
+		return nil, &ssawalk.UnsupportedError{Value: wrapperFunc, Reason: "unexpected wrapper func (len(Blocks) != 1)"}
 	}
 	block := wrapperFunc.Blocks[0]
 	if len(block.Instrs) < 2 {
 		// Instrs should be Call, (Extract,.., Extract), Return.
-		panic(fmt.Sprintf("unexpected wrapper func (len(Instrs) != 2) %v", wrapperFunc.Name()))
+		return nil, &ssawalk.UnsupportedError{Value: wrapperFunc, Reason: "unexpected wrapper func (len(Instrs) < 2)"}
 	}
 	call, ok := block.Instrs[0].(*ssa.Call)
 	if !ok {
-		panic(fmt.Sprintf("unexpected wrapper func (Instrs[0] != *ssa.Call) %v", wrapperFunc.Name()))
+		return nil, &ssawalk.UnsupportedError{Value: wrapperFunc, Reason: "unexpected wrapper func (Instrs[0] != *ssa.Call)"}
 	}
 	if call.Call.IsInvoke() {
 		// eg.Go calls interface method
@@ -53,7 +54,7 @@ func unwrap(wrapperFunc *ssa.Function) (*ssa.Function, error) {
 	}
 	fn, ok := call.Call.Value.(*ssa.Function)
 	if !ok {
-		panic(fmt.Sprintf("unexpected wrapper func (Call.Value != *ssa.Function) %v", wrapperFunc.Name()))
+		return nil, &ssawalk.UnsupportedError{Value: wrapperFunc, Reason: "unexpected wrapper func (Call.Value != *ssa.Function)"}
 	}
 	return fn, nil
 }
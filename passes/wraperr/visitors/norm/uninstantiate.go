@@ -1,8 +1,6 @@
 package norm
 
 import (
-	"errors"
-	"fmt"
 	"strings"
 
 	"golang.org/x/tools/go/ssa"
@@ -18,7 +16,7 @@ type visitorPlugin struct {
 
 func (p *visitorPlugin) VisitFunction(val *ssa.Function) error {
 	if p.ret != nil {
-		return errors.New("unexpected ret is not nil")
+		return &ssawalk.UnsupportedError{Value: val, Reason: "instantiation wrapper returns multiple funcs"}
 	}
 	p.ret = val
 	return nil
@@ -27,17 +25,17 @@ func (p *visitorPlugin) VisitFunction(val *ssa.Function) error {
 func (p *visitorPlugin) createOptions() []ssawalk.Option {
 	return []ssawalk.Option{
 		ssawalk.WithVisitFunction(p.VisitFunction),
-		ssawalk.WithVisitConst(func(_ *ssa.Const) error {
-			panic("unexpected const")
+		ssawalk.WithVisitConst(func(val *ssa.Const) error {
+			return &ssawalk.UnsupportedError{Value: val, Reason: "unexpected const in instantiation wrapper"}
 		}),
-		ssawalk.WithVisitAlloc(func(_ *ssa.Alloc) error {
-			panic("unexpected alloc")
+		ssawalk.WithVisitAlloc(func(val *ssa.Alloc) error {
+			return &ssawalk.UnsupportedError{Value: val, Reason: "unexpected alloc in instantiation wrapper"}
 		}),
-		ssawalk.WithVisitComplex(func(_ ssa.Value) error {
-			panic("unexpected complex")
+		ssawalk.WithVisitComplex(func(val ssa.Value) error {
+			return &ssawalk.UnsupportedError{Value: val, Reason: "unexpected complex value in instantiation wrapper"}
 		}),
-		ssawalk.WithVisitCallInvoke(func(_ *ssa.Call) error {
-			panic("unexpected call invoke")
+		ssawalk.WithVisitCallInvoke(func(val *ssa.Call) error {
+			return &ssawalk.UnsupportedError{Value: val, Reason: "unexpected call invoke in instantiation wrapper"}
 		}),
 	}
 }
@@ -55,7 +53,7 @@ func uninstantiate(fn *ssa.Function, indices []int) (*ssa.Function, error) {
 		}
 	}
 	if plugin.ret == nil {
-		return nil, fmt.Errorf("unexpected: walk fail to unwrap function %v", fn.Name())
+		return nil, &ssawalk.UnsupportedError{Value: fn, Reason: "walk fail to unwrap instantiation wrapper"}
 	}
 	return plugin.ret, nil
 }
@@ -1,31 +1,39 @@
 package wraperr
 
 import (
-	"encoding/json"
+	"cmp"
 	"flag"
 	"fmt"
 	"log/slog"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/buildssa"
 	"golang.org/x/tools/go/ssa"
 
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
 	"github.com/cloverrose/rpcguard/pkg/factutil"
 	"github.com/cloverrose/rpcguard/pkg/filter"
 	"github.com/cloverrose/rpcguard/pkg/graph"
+	"github.com/cloverrose/rpcguard/pkg/inventory"
 	"github.com/cloverrose/rpcguard/pkg/logger"
 	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
 	"github.com/cloverrose/rpcguard/pkg/signature"
+	"github.com/cloverrose/rpcguard/pkg/stats"
 
 	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph"
-	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/eg"
+	"github.com/cloverrose/rpcguard/passes/wraperr/callgraph/plugin/registry"
 )
 
 const (
-	doc               = "rpc_wraperr checks if errors returned in RPC method is wrapped by connect.NewError."
-	reportMsg         = "RPC method %s returns error that is not wrapped with connect.NewError"
-	packageKey        = "package"
-	unexpectedUnknown = "unexpected KindUnknown"
+	doc        = "rpc_wraperr checks if errors returned in RPC method is wrapped by connect.NewError."
+	reportMsg  = "RPC method %s returns error that is not wrapped with connect.NewError"
+	unknownMsg = "RPC method %s returns error that cannot be proven wrapped with connect.NewError: callee pkg %s is not in IncludePackages"
 )
 
 // Analyzer checks if RPC method returns error properly.
@@ -40,53 +48,94 @@ var Analyzer = &analysis.Analyzer{
 	FactTypes: []analysis.Fact{
 		&isErrorHandler{},
 	},
+	ResultType: reflect.TypeOf((*inventory.Result)(nil)),
 }
 
 func init() {
 	Analyzer.Flags.StringVar(&LogConfig.Level, "log.level", LogConfig.Level, "logging level. debug, info, warn, error")
 	Analyzer.Flags.StringVar(&LogConfig.File, "log.file", LogConfig.File, "log file path.")
+	Analyzer.Flags.StringVar(&LogConfig.Dir, "log.dir", LogConfig.Dir, "log directory path. one file per package.")
 	Analyzer.Flags.StringVar(&LogConfig.Format, "log.format", LogConfig.Format, "logging format. json or text")
 	Analyzer.Flags.StringVar(&ReportMode, "ReportMode", ReportMode, "reporting mode (RETURN, FUNCTION, BOTH)")
 	Analyzer.Flags.StringVar(&IncludePackages, "IncludePackages", IncludePackages, "include packages")
 	Analyzer.Flags.StringVar(&ExcludePackages, "ExcludePackages", ExcludePackages, "exclude packages")
 	Analyzer.Flags.StringVar(&ExcludeFiles, "ExcludeFiles", ExcludeFiles, "exclude files")
+	Analyzer.Flags.BoolVar(&SkipGenerated, "SkipGenerated", SkipGenerated, "skip generated files (default true)")
 	Analyzer.Flags.BoolVar(&EnableErrGroupAnalyzer, "EnableErrGroupAnalyzer", EnableErrGroupAnalyzer, "enable ErrGroupAnalyzer (default true)")
+	Analyzer.Flags.BoolVar(&StrictUnknown, "StrictUnknown", StrictUnknown, "report errors that cannot be proven because of IncludePackages (default true)")
+	Analyzer.Flags.StringVar(&PropagationModels, "PropagationModels", PropagationModels, "error propagation models")
+	Analyzer.Flags.StringVar(&DumpGraph, "DumpGraph", DumpGraph, "directory to write the error call graph of each package")
+	Analyzer.Flags.StringVar(&DumpGraphFormat, "DumpGraphFormat", DumpGraphFormat, "format of DumpGraph (dot, json)")
+	Analyzer.Flags.StringVar(&StatsDir, "StatsDir", StatsDir, "directory to write timing and statistics of each package")
 }
 
 func setupAndRun(pass *analysis.Pass) (any, error) {
-	closer, err := logger.SetDefault(LogConfig, pass)
+	log, closer, err := logger.New(LogConfig, pass)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := closer(); err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(os.Stderr, err)
 		}
 	}()
 
-	packageFilter, err = filter.New(IncludePackages, ExcludePackages)
+	result := &inventory.Result{}
+	packageFilter, modules, skipReason, err := filter.ForPass(pass, log, IncludePackages, ExcludePackages)
 	if err != nil {
 		return nil, err
 	}
+	if skipReason != "" {
+		result.SkipPackage(pass, skipReason)
+		return result, nil
+	}
 
 	// any files that are not excluded are target.
-	fileFilter, err = filter.New(`.*`, ExcludeFiles)
+	fileFilter, err := filter.New(`.*`, ExcludeFiles)
 	if err != nil {
 		return nil, err
 	}
 
-	return run(pass)
+	propagationModels, err := registry.Lookup(PropagationModels)
+	if err != nil {
+		return nil, err
+	}
+	if !EnableErrGroupAnalyzer {
+		propagationModels = slices.DeleteFunc(propagationModels, func(model registry.Model) bool {
+			return model.Name == registry.ModelErrGroup
+		})
+	}
+
+	// st is nil unless StatsDir is set, and nil st records nothing.
+	var st *stats.Stats
+	if StatsDir != "" {
+		st = stats.New(pass.Analyzer.Name, pass.Pkg.Path())
+	}
+	targets := &passTargets{modules: modules, packages: packageFilter, files: fileFilter, models: propagationModels}
+	result, err = run(pass, log, st, targets, result)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.WriteDir(StatsDir); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-//nolint:gocognit,gocyclo,cyclop // main routine
-func run(pass *analysis.Pass) (interface{}, error) {
+// run analyzes the package and adds verdicts of RPC methods to result.
+// Durations of phases and counters are recorded to st.
+//
+//nolint:gocognit,gocyclo,cyclop,funlen // main routine
+func run(pass *analysis.Pass, log *slog.Logger, st *stats.Stats, targets *passTargets, result *inventory.Result) (*inventory.Result, error) {
 	currentPackage := pass.Pkg.Path()
-	slog.Debug("analyzing package", slog.String(packageKey, currentPackage))
+	log.Debug("analyzing package")
 
 	// Phase1: Package is target?
-	if !packageFilter.IsTarget(currentPackage) {
-		slog.Debug("skip package (not target package)", slog.String(packageKey, currentPackage))
-		return nil, nil
+	if !targets.packages.IsTarget(currentPackage) {
+		reason := targets.packages.Explain(currentPackage).String()
+		log.Debug("skip package (not target package)", slog.String("reason", reason))
+		result.SkipPackage(pass, reason)
+		return result, nil
 	}
 
 	// Phase 2: Get SSA
@@ -94,32 +143,64 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	if !ok {
 		panic("failed to get SSA")
 	}
+	defer ssawalk.IndexFields(ssaData.Pkg.Prog)()
 
 	// Phase 3: Func is target?
+	end := st.Start("target")
 	targetSrcFuncs := make([]*ssa.Function, 0, len(ssaData.SrcFuncs))
 	for _, srcFunc := range ssaData.SrcFuncs {
-		if isTargetFunc(pass, srcFunc) {
+		if isTargetFunc(pass, log, targets, srcFunc) {
 			targetSrcFuncs = append(targetSrcFuncs, srcFunc)
 		}
 	}
+	end()
+	st.Add("funcs.src", len(ssaData.SrcFuncs))
+	st.Add("funcs.target", len(targetSrcFuncs))
 
 	// Phase 4: Build Call Graph
+	end = st.Start("callgraph")
 	cg := callgraph.New(signature.ErrIshIndices)
+	// notAnalyzed holds the first error of each func that can't be analyzed.
+	notAnalyzed := make(map[*ssa.Function]error)
+	markNotAnalyzed := func(srcFunc *ssa.Function, err error) {
+		// can't analyze srcFunc, treat badFunc.
+		if _, ok := notAnalyzed[srcFunc]; !ok {
+			notAnalyzed[srcFunc] = err
+		}
+		cg.MarkUnsupported(srcFunc)
+	}
 	for _, srcFunc := range targetSrcFuncs {
 		if err := cg.Scan(srcFunc); err != nil {
-			return nil, err
+			if !ssawalk.IsUnsupported(err) {
+				return nil, err
+			}
+			markNotAnalyzed(srcFunc, err)
 		}
 	}
-	slog.Debug("build callgraph", slog.Any("callgraph", cg))
+	end()
+	log.Debug("build callgraph", slog.Any("callgraph", cg))
 
-	// Phase 5: Analyze go.Wait and go.Go
-	if EnableErrGroupAnalyzer {
+	// Phase 5: Analyze error propagation models. E.g. eg.Wait and eg.Go, errors.Join
+	for _, model := range targets.models {
+		end := st.Start("model:" + model.Name)
 		for _, srcFunc := range targetSrcFuncs {
-			if err := cg.ScanWithPlugin(eg.Scan, srcFunc); err != nil {
-				return nil, err
+			if err := cg.ScanWithPlugin(model.Name, model.Scan, srcFunc); err != nil {
+				if !ssawalk.IsUnsupported(err) {
+					return nil, err
+				}
+				markNotAnalyzed(srcFunc, err)
 			}
 		}
-		slog.Debug("build callgraph with errgroup", slog.Any("callgraph", cg))
+		end()
+		if st != nil {
+			st.Add("model:"+model.Name+".substitutions", countSubstitutions(cg, model.Name))
+		}
+		log.Debug("build callgraph with "+model.Name, slog.Any("callgraph", cg))
+	}
+	for _, srcFunc := range targetSrcFuncs {
+		if err, ok := notAnalyzed[srcFunc]; ok && !(SkipGenerated && filter.IsGenerated(pass, srcFunc.Pos())) {
+			rpcmethod.ReportNotAnalyzed(pass, srcFunc, err)
+		}
 	}
 
 	// closure func (ends with $1) Object() == nil, then we can't export facts.
@@ -127,83 +208,150 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	factWrapper := factutil.NewFactWrapper[*isErrorHandler](pass)
 
 	// Phase 6: Export obvious facts.
+	end = st.Start("obvious")
 	for _, srcFunc := range targetSrcFuncs {
 		info := cg.GetReturnInfo(srcFunc)
 		if info == nil {
 			panic(fmt.Sprintf("unexpected info not found for srcFunc: %s", srcFunc.Name()))
 		}
+		if info.IsUnsupported() {
+			st.Add("funcs.unsupported", 1)
+		}
 		if info.IsObviouslyBad() {
 			factWrapper.Export(srcFunc, &isErrorHandler{Kind: KindBad})
 		}
 		if info.IsObviouslyOK() {
-			factWrapper.Export(srcFunc, &isErrorHandler{Kind: KindOK})
+			factWrapper.Export(srcFunc, &isErrorHandler{
+				Kind:         KindOK,
+				Params:       info.GetParams(),
+				ParentParams: info.GetParentParams(),
+			})
 		}
 	}
 
+	end()
+
 	// Phase 7: Create SCCs (this sccs are topologically sorted)
+	end = st.Start("scc")
 	g := cg.Convert()
-	slog.Debug("build graph", slog.Any("graph", g))
+	log.Debug("build graph", slog.Any("graph", g))
 	sccs := graph.Decomposition(g)
-	slog.Debug("Strongly Connected Components", slog.Any("sccs", sccs))
+	log.Debug("Strongly Connected Components", slog.Any("sccs", graph.SCCs[*ssa.Function](sccs)))
+	end()
+	st.Add("graph.vertices", len(g.Vertices()))
+	st.Add("graph.edges", g.NumEdges())
+	st.Add("sccs", len(sccs))
+	for _, scc := range sccs {
+		st.Max("scc.size.max", len(scc))
+		if len(scc) > 1 {
+			st.Add("sccs.cyclic", 1)
+		}
+	}
 
-	if err := markSCCs(pass, sccs, factWrapper, cg); err != nil {
+	end = st.Start("mark")
+	summary, err := markSCCs(pass, log, targets, sccs, factWrapper, cg)
+	if err != nil {
 		return nil, err
 	}
+	end()
+	if len(summary) != 0 {
+		log.Warn("some funcs cannot be proven because callee packages are not in IncludePackages", slog.Any("excludedPackages", summary))
+	}
 
-	// Phase 8: Check RPC method is marked with bad or not.
 	rpcChecker := rpcmethod.BuildChecker(pass)
+	end = st.Start("dump")
+	if err := dumpGraph(pass, cg, sccs, factWrapper, rpcChecker); err != nil {
+		return nil, err
+	}
+	end()
+	defer func() {
+		counts := factWrapper.Counts()
+		st.Add("facts.exported", counts.Exported)
+		st.Add("facts.localHits", counts.LocalHits)
+		st.Add("facts.imported", counts.Imported)
+		st.Add("facts.misses", counts.Misses)
+	}()
+
+	// Phase 8: Check RPC method is marked with bad or not.
 	if rpcChecker == nil {
-		slog.Debug("skip package (no rpc method types)", slog.String(packageKey, currentPackage))
-		return nil, nil
+		log.Debug("skip package (no rpc method types)")
+		result.SkipPackage(pass, "RPC method types are not resolved (connectrpc.com/connect is not imported)")
+		return result, nil
 	}
-	for _, fn := range targetSrcFuncs {
+	// iterate all funcs, so that RPC methods in excluded files are listed in the inventory.
+	end = st.Start("report")
+	defer end()
+	for _, fn := range ssaData.SrcFuncs {
 		if !rpcChecker.IsRPCMethod(fn) {
 			continue
 		}
-		slog.Info("found RPC method", logger.Attr(fn))
+		st.Add("rpcMethods", 1)
+		if e := targets.files.Explain(pass.Fset.Position(fn.Pos()).Filename); !e.Target {
+			result.Add(fn, inventory.VerdictSkipped, "file is excluded by "+strconv.Quote(e.Exclude))
+			continue
+		}
+		if SkipGenerated && filter.IsGenerated(pass, fn.Pos()) {
+			log.Debug("skip RPC method (generated file)", logger.Attr(fn))
+			result.Add(fn, inventory.VerdictSkipped, "generated file")
+			continue
+		}
+		log.Info("found RPC method", logger.Attr(fn))
 
 		fact, ok := factWrapper.Import(fn)
-		if ok {
-			switch fact.Kind {
-			case KindUnknown:
-				panic(unexpectedUnknown)
-			case KindBad:
-				if ReportMode == reportModeFunction || ReportMode == reportModeBoth {
-					pass.Reportf(fn.Pos(), reportMsg, fn.Name())
-				}
-				if ReportMode == reportModeReturn || ReportMode == reportModeBoth {
-					info := cg.GetReturnInfo(fn)
-					for _, rtn := range info.GetReturns() {
-						reportReturn(pass, factWrapper, info, fn, rtn)
-					}
+		if !ok {
+			result.Add(fn, inventory.VerdictUnknown, "no fact")
+			continue
+		}
+		switch {
+		case fact.Kind == KindOK:
+			result.Add(fn, inventory.VerdictOK, "")
+			continue
+		case fact.Kind == KindUnknown:
+			result.Add(fn, inventory.VerdictUnknown, "callee packages are not in IncludePackages: "+strings.Join(fact.Packages, ", "))
+		case cg.GetReturnInfo(fn).IsUnsupported():
+			result.Add(fn, inventory.VerdictBad, "cannot be analyzed, treated as bad")
+		default:
+			result.Add(fn, inventory.VerdictBad, "")
+		}
+		if fact.Kind == KindUnknown && !StrictUnknown {
+			log.Info("skip RPC method (unknown)", logger.Attr(fn), slog.Any("packages", fact.Packages))
+			continue
+		}
+		if ReportMode == reportModeFunction || ReportMode == reportModeBoth {
+			reportFunc(pass, fn, fact)
+		}
+		if ReportMode == reportModeReturn || ReportMode == reportModeBoth {
+			info := cg.GetReturnInfo(fn)
+			for _, rtn := range info.GetReturns() {
+				if rtn.Block() == fn.Recover {
+					// return in recover block is synthetic, it returns the same values with the return after rundefers.
+					continue
 				}
-			case KindOK:
+				reportReturn(pass, log, factWrapper, info, fn, rtn)
 			}
 		}
 	}
 
-	return nil, nil
+	return result, nil
 }
 
-func reportReturn(
-	pass *analysis.Pass,
-	factWrapper *factutil.FactWrapper[*isErrorHandler],
-	info *callgraph.FuncInfo,
-	fn *ssa.Function,
-	rtn *ssa.Return,
-) {
-	for _, toFunc := range info.GetToFuncs(rtn) {
-		if fact, ok := factWrapper.Import(toFunc); ok && fact.Kind == KindBad {
-			pass.Reportf(rtn.Pos(), reportMsg, fn.Name())
-			return
+// countSubstitutions returns the number of funcs replaced by the propagation model in cg.
+func countSubstitutions(cg *callgraph.CallGraph, model string) int {
+	var n int
+	for _, fn := range cg.Funcs() {
+		info := cg.GetReturnInfo(fn)
+		for _, rtn := range info.GetReturns() {
+			for _, sub := range info.GetSource(rtn).Substitutions {
+				if sub.Model == model {
+					n++
+				}
+			}
 		}
 	}
-	if info.IsObviouslyBadReturn(rtn) {
-		pass.Reportf(rtn.Pos(), reportMsg, fn.Name())
-	}
+	return n
 }
 
-func isTargetFunc(pass *analysis.Pass, srcFunc *ssa.Function) bool {
+func isTargetFunc(pass *analysis.Pass, log *slog.Logger, targets *passTargets, srcFunc *ssa.Function) bool {
 	if srcFunc == nil {
 		panic("srcFunc is nil")
 	}
@@ -214,11 +362,11 @@ func isTargetFunc(pass *analysis.Pass, srcFunc *ssa.Function) bool {
 	if srcFunc.Pkg.Pkg == nil {
 		panic("srcFunc.Pkg.Pkg is nil")
 	}
-	if !packageFilter.IsTarget(srcFunc.Pkg.Pkg.Path()) {
-		panic("!packageFilter.IsTarget(srcFunc.Pkg.Pkg.Path())")
+	if !targets.packages.IsTarget(srcFunc.Pkg.Pkg.Path()) {
+		panic("!targets.packages.IsTarget(srcFunc.Pkg.Pkg.Path())")
 	}
-	if !fileFilter.IsTarget(fileName) {
-		slog.Debug("skip Function (non target file)", logger.Attr(srcFunc))
+	if !targets.files.IsTarget(fileName) {
+		log.Debug("skip Function (non target file)", logger.Attr(srcFunc))
 		return false
 	}
 
@@ -227,20 +375,21 @@ func isTargetFunc(pass *analysis.Pass, srcFunc *ssa.Function) bool {
 	return len(indices) != 0
 }
 
-type SCCs [][]*ssa.Function
+// unknownSummary counts funcs made unknown by each package not in IncludePackages.
+type unknownSummary map[string]int
 
-func (sccs SCCs) LogValue() slog.Value {
-	coreData := make([][]string, len(sccs))
-	for i, scc := range sccs {
-		coreData2 := make([]string, len(scc))
-		for j, fn := range scc {
-			coreData2[j] = fn.String()
+// LogValue returns packages in descending order of the count.
+func (summary unknownSummary) LogValue() slog.Value {
+	packages := slices.Collect(maps.Keys(summary))
+	slices.SortFunc(packages, func(a, b string) int {
+		if c := cmp.Compare(summary[b], summary[a]); c != 0 {
+			return c
 		}
-		coreData[i] = coreData2
-	}
-	jsonBytes, err := json.Marshal(coreData)
-	if err != nil {
-		return slog.Value{}
+		return cmp.Compare(a, b)
+	})
+	attrs := make([]slog.Attr, len(packages))
+	for i, pkg := range packages {
+		attrs[i] = slog.Int(pkg, summary[pkg])
 	}
-	return slog.StringValue(string(jsonBytes))
+	return slog.GroupValue(attrs...)
 }
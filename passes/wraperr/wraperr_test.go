@@ -1,6 +1,10 @@
 package wraperr_test
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -8,6 +12,8 @@ import (
 
 	"github.com/gostaticanalysis/testutil"
 
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+
 	"github.com/cloverrose/rpcguard/passes/wraperr"
 )
 
@@ -15,11 +21,87 @@ func Test(t *testing.T) {
 	t.Parallel()
 	testdata := analysistest.TestData()
 	testdata = testutil.WithModules(t, testdata, nil)
-	wraperr.LogConfig.Level = "INFO"
-	wraperr.ReportMode = "BOTH"
-	wraperr.EnableErrGroupAnalyzer = true
-	pkgs := "a/a01core,a/a02phi,a/a03interface,a/a04closure,a/a05global,a/a06parameter,a/a07generics,a/a08import/a,a/a08import/includedpkg,a/a09cyclic,a/a10defer,a/a21returnindex,eg/eg01core,eg/eg02generics,eg/eg03interface"
-	wraperr.IncludePackages = "^(a/a01core|a/a02phi|a/a03interface|a/a04closure|a/a05global|a/a06parameter|a/a07generics|a/a08import/a|a/a08import/includedpkg|a/a09cyclic|a/a10defer|a/a21returnindex|eg/eg01core|eg/eg02generics|eg/eg03interface)$"
-	wraperr.ExcludePackages = "(.+/)?vendor$"
+	configtest.Set(t, &wraperr.LogConfig.Level, "INFO")
+	configtest.Set(t, &wraperr.ReportMode, "BOTH")
+	configtest.Set(t, &wraperr.EnableErrGroupAnalyzer, true)
+	pkgs := "a/a01core,a/a02phi,a/a03interface,a/a04closure,a/a05global,a/a06parameter,a/a07generics,a/a08import/a,a/a08import/includedpkg,a/a09cyclic,a/a10defer,a/a11field,a/a12builtin,a/a13container,a/a14generated,a/a21returnindex,eg/eg01core,eg/eg02generics,eg/eg03interface,eg/eg04alias,agg/agg01errors,agg/agg02multierr,agg/agg03multierror,agg/agg04conc,agg/agg05fmt,agg/agg06pkgerrors"
+	configtest.Set(t, &wraperr.IncludePackages, "^(a/a01core|a/a02phi|a/a03interface|a/a04closure|a/a05global|a/a06parameter|a/a07generics|a/a08import/a|a/a08import/includedpkg|a/a09cyclic|a/a10defer|a/a11field|a/a12builtin|a/a13container|a/a14generated|a/a21returnindex|eg/eg01core|eg/eg02generics|eg/eg03interface|eg/eg04alias|agg/agg01errors|agg/agg02multierr|agg/agg03multierror|agg/agg04conc|agg/agg05fmt|agg/agg06pkgerrors)$")
+	configtest.Set(t, &wraperr.ExcludePackages, "(.+/)?vendor$")
 	analysistest.Run(t, testdata, wraperr.Analyzer, strings.Split(pkgs, ",")...)
 }
+
+func TestSuggestedFixes(t *testing.T) {
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &wraperr.LogConfig.Level, "ERROR")
+	configtest.Set(t, &wraperr.ReportMode, "RETURN")
+	configtest.Set(t, &wraperr.IncludePackages, "^a/a15fix$")
+	// testutil.WithModules adds //line directive to source files, thus golden file has it too.
+	analysistest.RunWithSuggestedFixes(t, testdata, wraperr.Analyzer, "a/a15fix")
+}
+
+func TestDumpGraph(t *testing.T) {
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	dir := t.TempDir()
+	configtest.Set(t, &wraperr.LogConfig.Level, "ERROR")
+	configtest.Set(t, &wraperr.ReportMode, "BOTH")
+	configtest.Set(t, &wraperr.IncludePackages, "^eg/eg01core$")
+	configtest.Set(t, &wraperr.DumpGraph, dir)
+	configtest.Set(t, &wraperr.DumpGraphFormat, "dot,json")
+	analysistest.Run(t, testdata, wraperr.Analyzer, "eg/eg01core")
+
+	data, err := os.ReadFile(filepath.Join(dir, "eg%2Feg01core.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type funcDump struct {
+		Name      string `json:"name"`
+		RPCMethod bool   `json:"rpcMethod"`
+		Kind      string `json:"kind"`
+		Returns   []struct {
+			Substitutions []struct {
+				Model string `json:"model"`
+				From  string `json:"from"`
+			} `json:"substitutions"`
+		} `json:"returns"`
+	}
+	var got struct {
+		Package string     `json:"package"`
+		Funcs   []funcDump `json:"funcs"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Package != "eg/eg01core" {
+		t.Errorf("package = %q, want eg/eg01core", got.Package)
+	}
+	i := slices.IndexFunc(got.Funcs, func(fn funcDump) bool {
+		return fn.Name == "(*eg/eg01core.App).GoBadClosure"
+	})
+	if i < 0 {
+		t.Fatalf("GoBadClosure not found in %s", data)
+	}
+	fn := got.Funcs[i]
+	if !fn.RPCMethod || fn.Kind != "bad" {
+		t.Errorf("GoBadClosure rpcMethod = %v, kind = %q, want true, bad", fn.RPCMethod, fn.Kind)
+	}
+	var models []string
+	for _, rt := range fn.Returns {
+		for _, sub := range rt.Substitutions {
+			models = append(models, sub.Model+":"+sub.From)
+		}
+	}
+	// errgroup is vendored in testdata.
+	if !slices.Contains(models, "errgroup:(*eg/vendor/golang.org/x/sync/errgroup.Group).Wait") {
+		t.Errorf("substitutions = %v, want errgroup substitution of Wait", models)
+	}
+
+	dot, err := os.ReadFile(filepath.Join(dir, "eg%2Feg01core.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(dot), `digraph "eg/eg01core" {`) || !strings.Contains(string(dot), "via errgroup") {
+		t.Errorf("unexpected dot:\n%s", dot)
+	}
+}
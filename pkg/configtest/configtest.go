@@ -0,0 +1,13 @@
+// Package configtest provides helpers to set configuration variables of analyzers in tests.
+package configtest
+
+import "testing"
+
+// Set sets value to the configuration variable p, and restores the previous value when the test finishes.
+// Configuration variables are package globals shared by tests, thus tests must not leave them modified.
+func Set[T any](t testing.TB, p *T, value T) {
+	t.Helper()
+	old := *p
+	*p = value
+	t.Cleanup(func() { *p = old })
+}
@@ -0,0 +1,251 @@
+package ssawalk
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// This file contains channel and map support.
+// Values received from channel are traced to values sent to the channel,
+// and values read from map are traced to values stored to the map.
+/*
+	func Foo() error {
+		errCh := make(chan error, 1)   // t0 = make chan error 1:int
+		go func() {
+			errCh <- do()              // send t0 <- t1 in Foo$1
+		}()
+		return <-errCh                 // t2 = <-t0
+	}
+*/
+
+// ReceivedValues returns values that are source of val which is received from channel or read from map.
+// val is one of
+//   - UnOp ARROW: v := <-ch, v, ok := <-ch, for v := range ch
+//   - Extract from Select: case v := <-ch:
+//   - Lookup: v := m[k], v, ok := m[k]
+//   - Extract from Next: for _, v := range m
+//
+// ok is false if val is none of them, or the channel (map) is not created in the function
+// or escapes (E.g. passed to other function), then sends (stores) can't be tracked.
+func ReceivedValues(val ssa.Value) (values []ssa.Value, ok bool) {
+	switch val := val.(type) {
+	case *ssa.UnOp:
+		if val.Op != token.ARROW {
+			return nil, false
+		}
+		return elemValues(val.X)
+	case *ssa.Lookup:
+		if _, isMap := val.X.Type().Underlying().(*types.Map); !isMap {
+			return nil, false
+		}
+		return elemValues(val.X)
+	case *ssa.Extract:
+		switch tuple := val.Tuple.(type) {
+		case *ssa.Select:
+			ch, ok := selectRecvChan(tuple, val.Index)
+			if !ok {
+				return nil, false
+			}
+			return elemValues(ch)
+		case *ssa.Next:
+			// Next returns (ok, key, value).
+			const valueIndex = 2
+			rng, isRange := tuple.Iter.(*ssa.Range)
+			if tuple.IsString || !isRange || val.Index != valueIndex {
+				return nil, false
+			}
+			return elemValues(rng.X)
+		}
+	}
+	return nil, false
+}
+
+// isReceived returns true if val should be walked with ReceivedValues.
+func isReceived(val ssa.Value) bool {
+	switch val := val.(type) {
+	case *ssa.UnOp:
+		return val.Op == token.ARROW
+	case *ssa.Lookup:
+		return true
+	case *ssa.Extract:
+		switch val.Tuple.(type) {
+		case *ssa.Select, *ssa.Next:
+			return true
+		}
+	}
+	return false
+}
+
+// selectRecvChan returns the channel of receive case which value is Extract(sel, index).
+// Select returns (index, recvOk, r_0, ..., r_n-1), r_i is received value of i-th receive case.
+func selectRecvChan(sel *ssa.Select, index int) (ssa.Value, bool) {
+	const firstRecvIndex = 2
+	recv := index - firstRecvIndex
+	if recv < 0 {
+		return nil, false
+	}
+	for _, state := range sel.States {
+		if state.Dir != types.RecvOnly {
+			continue
+		}
+		if recv == 0 {
+			return state.Chan, true
+		}
+		recv--
+	}
+	return nil, false
+}
+
+// elemValues returns values sent to the channel or stored to the map.
+func elemValues(container ssa.Value) ([]ssa.Value, bool) {
+	made, ok := containerOrigin(container)
+	if !ok {
+		return nil, false
+	}
+	return storedElems(made)
+}
+
+// containerOrigin returns MakeChan or MakeMap that container comes from.
+func containerOrigin(container ssa.Value) (ssa.Value, bool) {
+	switch container := container.(type) {
+	case *ssa.MakeChan, *ssa.MakeMap:
+		return container, true
+	case *ssa.ChangeType:
+		// E.g. chan error to <-chan error
+		return containerOrigin(container.X)
+	case *ssa.UnOp:
+		// load from variable captured by closure.
+		if container.Op != token.MUL {
+			return nil, false
+		}
+		return addrOrigin(container.X)
+	case *ssa.FreeVar:
+		bindings, ok := closureBindings(container)
+		if !ok || len(bindings) != 1 {
+			return nil, false
+		}
+		return containerOrigin(bindings[0])
+	}
+	return nil, false
+}
+
+// addrOrigin returns MakeChan or MakeMap that is stored to addr.
+// Other stores to addr are checked by storedElems.
+func addrOrigin(addr ssa.Value) (ssa.Value, bool) {
+	switch addr := addr.(type) {
+	case *ssa.Alloc:
+		for _, instr := range *addr.Referrers() {
+			if store, ok := instr.(*ssa.Store); ok && store.Addr == addr {
+				return containerOrigin(store.Val)
+			}
+		}
+	case *ssa.FreeVar:
+		bindings, ok := closureBindings(addr)
+		if !ok || len(bindings) != 1 {
+			return nil, false
+		}
+		return addrOrigin(bindings[0])
+	}
+	return nil, false
+}
+
+// storedElems returns values sent to (stored to) made which is MakeChan or MakeMap.
+// It follows aliases of made, E.g. variable captured by closure, and sends (stores) in closures.
+// ok is false if made or its alias escapes (E.g. passed to other function).
+//
+//nolint:gocognit,gocyclo,cyclop // check each kind of referrers.
+func storedElems(made ssa.Value) ([]ssa.Value, bool) {
+	aliases := map[ssa.Value]struct{}{made: {}}
+	isAlias := func(v ssa.Value) bool {
+		_, ok := aliases[v]
+		return ok
+	}
+	queue := []ssa.Value{made}
+	addAlias := func(v ssa.Value) {
+		if !isAlias(v) {
+			aliases[v] = struct{}{}
+			queue = append(queue, v)
+		}
+	}
+	var values []ssa.Value
+	for len(queue) > 0 {
+		alias := queue[0]
+		queue = queue[1:]
+		refs := alias.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, instr := range *refs {
+			switch instr := instr.(type) {
+			case *ssa.Send:
+				if isAlias(instr.X) {
+					// channel itself is sent to other channel.
+					return nil, false
+				}
+				values = append(values, instr.X)
+			case *ssa.Select:
+				for _, state := range instr.States {
+					if state.Send != nil && isAlias(state.Send) {
+						return nil, false
+					}
+					if state.Chan == alias && state.Dir == types.SendOnly {
+						values = append(values, state.Send)
+					}
+				}
+			case *ssa.MapUpdate:
+				if instr.Map != alias {
+					// map itself is stored to other map.
+					return nil, false
+				}
+				values = append(values, instr.Value)
+			case *ssa.Store:
+				if instr.Addr == alias {
+					// variable holding container is overwritten.
+					if !isAlias(instr.Val) {
+						return nil, false
+					}
+					continue
+				}
+				// container is stored to variable.
+				if _, ok := instr.Addr.(*ssa.Alloc); !ok {
+					return nil, false
+				}
+				addAlias(instr.Addr)
+			case *ssa.UnOp:
+				if instr.Op == token.MUL {
+					// load from variable.
+					addAlias(instr)
+				}
+				// otherwise receive.
+			case *ssa.Lookup, *ssa.Range, *ssa.DebugRef:
+				// read or iterate.
+				continue
+			case ssa.CallInstruction:
+				if _, ok := instr.Common().Value.(*ssa.Builtin); !ok {
+					// passed to function, we can't track sends (stores).
+					return nil, false
+				}
+				// E.g. close, defer close, len, delete
+			case *ssa.ChangeType:
+				addAlias(instr)
+			case *ssa.MakeClosure:
+				fn, isFunc := instr.Fn.(*ssa.Function)
+				for i, binding := range instr.Bindings {
+					if binding != alias {
+						continue
+					}
+					if !isFunc || i >= len(fn.FreeVars) {
+						return nil, false
+					}
+					addAlias(fn.FreeVars[i])
+				}
+			default:
+				// E.g. stored to field, passed to goroutine.
+				return nil, false
+			}
+		}
+	}
+	return values, true
+}
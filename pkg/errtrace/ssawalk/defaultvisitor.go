@@ -1,9 +1,6 @@
 package ssawalk
 
 import (
-	"fmt"
-	"regexp"
-
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -12,8 +9,12 @@ type options struct {
 	visitConst      func(val *ssa.Const) error
 	visitAlloc      func(val *ssa.Alloc) error
 	visitComplex    func(val ssa.Value) error
+	visitRecover    func(val *ssa.Builtin) error
 	visitCall       func(val *ssa.Call) error
 	visitCallInvoke func(val *ssa.Call) error
+	handlers        []handler
+	callDescent     bool
+	anyType         bool
 }
 
 type Option interface {
@@ -64,6 +65,20 @@ func WithVisitComplex(f func(val ssa.Value) error) Option {
 	return visitComplexOption(f)
 }
 
+type visitRecoverOption func(val *ssa.Builtin) error
+
+func (f visitRecoverOption) apply(opts *options) {
+	opts.visitRecover = f
+}
+
+// WithVisitRecover registers f for recover builtin, which returns the value passed to panic.
+// Without it, Walk returns UnsupportedError for recovered values.
+//
+//nolint:ireturn // for Uber option pattern.
+func WithVisitRecover(f func(val *ssa.Builtin) error) Option {
+	return visitRecoverOption(f)
+}
+
 type visitCallOption func(val *ssa.Call) error
 
 func (f visitCallOption) apply(opts *options) {
@@ -100,8 +115,47 @@ func NewDefaultVisitorWith(opts ...Option) *DefaultVisitor {
 	}
 }
 
-//nolint:ireturn,gocognit,cyclop // for Visitor pattern.
+//nolint:ireturn // for Visitor pattern.
 func (v DefaultVisitor) Visit(value ssa.Value) (Visitor, error) {
+	return v.VisitPath(value, Path{value})
+}
+
+// VisitPath calls handlers registered by On, then falls back to the default behavior.
+//
+//nolint:ireturn // for Visitor pattern.
+func (v DefaultVisitor) VisitPath(value ssa.Value, path Path) (Visitor, error) {
+	for _, h := range v.opts.handlers {
+		policy, matched, err := h(value, path)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		switch policy {
+		case PolicyDefault:
+			continue
+		case PolicyDescend:
+			return v, nil
+		case PolicyStop:
+			return nil, nil
+		case PolicyError:
+			return nil, &UnsupportedError{Value: value, Reason: "rejected by policy"}
+		}
+	}
+	return v.visitDefault(value)
+}
+
+func (v DefaultVisitor) descendsIntoCalls() bool {
+	return v.opts.callDescent
+}
+
+func (v DefaultVisitor) tracksAnyType() bool {
+	return v.opts.anyType
+}
+
+//nolint:ireturn,gocognit,cyclop // for Visitor pattern.
+func (v DefaultVisitor) visitDefault(value ssa.Value) (Visitor, error) {
 	switch value := value.(type) {
 	case *ssa.Function:
 		if v.opts.visitFunction != nil {
@@ -118,8 +172,9 @@ func (v DefaultVisitor) Visit(value ssa.Value) (Visitor, error) {
 		}
 		return nil, nil
 	case *ssa.Alloc:
-		if isOKDefer(value) {
-			return nil, nil
+		if _, ok := allocStoredValues(value, v.opts.anyType); ok {
+			// error variable (or variable of any type with WithAnyType), values stored to it should be walked further.
+			return v, nil
 		}
 		if v.opts.visitAlloc != nil {
 			if err := v.opts.visitAlloc(value); err != nil {
@@ -127,17 +182,77 @@ func (v DefaultVisitor) Visit(value ssa.Value) (Visitor, error) {
 			}
 		}
 		return nil, nil
-	case *ssa.FreeVar, *ssa.Parameter, *ssa.Global, *ssa.MakeMap, *ssa.MakeChan,
-		*ssa.MakeSlice, *ssa.Slice, *ssa.FieldAddr, *ssa.Field, *ssa.IndexAddr,
-		*ssa.Index, *ssa.Lookup, *ssa.Select, *ssa.Range, *ssa.Next:
+	case *ssa.FreeVar:
+		if _, ok := freeVarBindings(value, v.opts.anyType); ok {
+			// captured variable, values bound to it should be walked further.
+			return v, nil
+		}
+		if v.opts.visitComplex != nil {
+			if err := v.opts.visitComplex(value); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case *ssa.FieldAddr, *ssa.Field:
+		if _, ok := FieldStoredValues(value); ok {
+			// struct field, values stored to it should be walked further.
+			return v, nil
+		}
+		if v.opts.visitComplex != nil {
+			if err := v.opts.visitComplex(value); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case *ssa.UnOp, *ssa.Lookup, *ssa.Extract:
+		if !isReceived(value) {
+			return v, nil
+		}
+		if _, ok := ReceivedValues(value); ok {
+			// received from channel or read from map, values sent (stored) to it should be walked further.
+			return v, nil
+		}
+		if v.opts.visitComplex != nil {
+			if err := v.opts.visitComplex(value); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case *ssa.Parameter, *ssa.Global, *ssa.MakeMap, *ssa.MakeChan,
+		*ssa.MakeSlice, *ssa.Slice, *ssa.IndexAddr,
+		*ssa.Index, *ssa.Select, *ssa.Range, *ssa.Next:
+		if v.opts.visitComplex != nil {
+			if err := v.opts.visitComplex(value); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case *ssa.Builtin:
+		if !isRecover(value) {
+			// Builtin is reached as callee. Builtins other than recover never return error or func.
+			if v.opts.visitComplex != nil {
+				if err := v.opts.visitComplex(value); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}
+		if v.opts.visitRecover == nil {
+			return nil, &UnsupportedError{Value: value, Reason: reasonRecovered}
+		}
+		if err := v.opts.visitRecover(value); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case *ssa.BinOp, *ssa.Convert, *ssa.MultiConvert:
+		// BinOp, Convert and MultiConvert produce basic values (E.g. bool, number, string, unsafe.Pointer).
+		// Thus, they are not source of error and treated as complex.
 		if v.opts.visitComplex != nil {
 			if err := v.opts.visitComplex(value); err != nil {
 				return nil, err
 			}
 		}
 		return nil, nil
-	case *ssa.Builtin, *ssa.BinOp, *ssa.Convert, *ssa.MultiConvert:
-		return nil, fmt.Errorf("unexpected value: %s %T", value, value)
 	case *ssa.Call:
 		if value.Call.IsInvoke() {
 			if v.opts.visitCallInvoke != nil {
@@ -157,21 +272,3 @@ func (v DefaultVisitor) Visit(value ssa.Value) (Visitor, error) {
 	}
 	return v, nil
 }
-
-// okDeferPattern are `local error ()` or `local error (err1)`
-// err1 in paren is variable name.
-// okDefer means func has a defer block and the defer block does not assign error.
-/*
-	func Foo() (x string, err1 error) {
-		defer func () {
-			x = "set in defer"
-		}
-		return "ok", nil
-	}
-*/
-var okDeferPattern = regexp.MustCompile(`local error \(.*\)`)
-
-func isOKDefer(value *ssa.Alloc) bool {
-	str := value.String()
-	return okDeferPattern.MatchString(str)
-}
@@ -0,0 +1,183 @@
+package ssawalk
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// This file detects the defer-based error wrapping idiom.
+/*
+	func (s *Server) Hello(ctx context.Context, req *connect.Request[Msg]) (res *connect.Response[Msg], err error) {
+		defer func() {
+			if err != nil {
+				err = connect.NewError(toCode(err), err)
+			}
+		}()
+		if err := s.do(ctx); err != nil {
+			return nil, err  // <- overwritten in defer
+		}
+		return connect.NewResponse(&Msg{}), nil
+	}
+*/
+// The deferred closure overwrites named result err on every non-nil path,
+// so the returned error is nil or the value stored in the closure.
+
+// isResultSlot returns true if alloc is named result, i.e. it is loaded after rundefers and returned.
+func isResultSlot(alloc *ssa.Alloc) bool {
+	for _, instr := range *alloc.Referrers() {
+		load, ok := instr.(*ssa.UnOp)
+		if !ok || load.Op != token.MUL || load.Referrers() == nil {
+			continue
+		}
+		returned := false
+		for _, ref := range *load.Referrers() {
+			if _, ok := ref.(*ssa.Return); ok {
+				returned = true
+			}
+		}
+		if returned && afterRunDefers(load) {
+			return true
+		}
+	}
+	return false
+}
+
+// afterRunDefers returns true if instr is preceded by rundefers in the same block.
+func afterRunDefers(instr ssa.Instruction) bool {
+	for _, prev := range instr.Block().Instrs {
+		if prev == instr {
+			return false
+		}
+		if _, ok := prev.(*ssa.RunDefers); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasWrappingDefer returns true if alloc is captured by deferred closure that
+// runs on every return and overwrites alloc on every path where alloc is not nil.
+func hasWrappingDefer(alloc *ssa.Alloc) bool {
+	fn := alloc.Parent()
+	for _, instr := range *alloc.Referrers() {
+		closure, ok := instr.(*ssa.MakeClosure)
+		if !ok || closure.Referrers() == nil {
+			continue
+		}
+		for _, ref := range *closure.Referrers() {
+			deferInstr, ok := ref.(*ssa.Defer)
+			if !ok || deferInstr.Call.Value != closure || !dominatesReturns(fn, deferInstr) {
+				continue
+			}
+			closureFn, ok := closure.Fn.(*ssa.Function)
+			if !ok || len(closureFn.Blocks) == 0 {
+				continue
+			}
+			for _, freeVar := range boundFreeVars(closure, alloc) {
+				if freeVar != nil && overwritesNonNil(closureFn, freeVar) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// dominatesReturns returns true if deferInstr is executed before every return of fn.
+// The recover block is ignored, since it is reached only after defers run.
+func dominatesReturns(fn *ssa.Function, deferInstr *ssa.Defer) bool {
+	deferBlock := deferInstr.Block()
+	for _, block := range fn.Blocks {
+		if block == fn.Recover || len(block.Instrs) == 0 {
+			continue
+		}
+		if _, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return); !ok {
+			continue
+		}
+		if !deferBlock.Dominates(block) {
+			return false
+		}
+		if block == deferBlock && !afterInstr(block, deferInstr, block.Instrs[len(block.Instrs)-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// afterInstr returns true if instr appears after prev in block.
+func afterInstr(block *ssa.BasicBlock, prev, instr ssa.Instruction) bool {
+	for _, i := range block.Instrs {
+		if i == instr {
+			return false
+		}
+		if i == prev {
+			return true
+		}
+	}
+	return false
+}
+
+// overwritesNonNil returns true if every path of fn from entry to return stores to addr,
+// except the paths where *addr is checked to be nil.
+func overwritesNonNil(fn *ssa.Function, addr *ssa.FreeVar) bool {
+	visited := make(map[*ssa.BasicBlock]bool)
+	var visit func(block *ssa.BasicBlock) bool
+	visit = func(block *ssa.BasicBlock) bool {
+		if visited[block] {
+			// loop, other paths are checked from the first visit.
+			return true
+		}
+		visited[block] = true
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Store:
+				if instr.Addr == addr {
+					return true
+				}
+			case *ssa.Return:
+				return false
+			case *ssa.Panic:
+				return true
+			case *ssa.If:
+				if nonNilSucc, ok := nonNilSuccessor(instr, addr); ok {
+					return visit(nonNilSucc)
+				}
+			}
+		}
+		for _, succ := range block.Succs {
+			if !visit(succ) {
+				return false
+			}
+		}
+		return true
+	}
+	return visit(fn.Blocks[0])
+}
+
+// nonNilSuccessor returns successor block where *addr is not nil if ifInstr checks *addr != nil or *addr == nil.
+func nonNilSuccessor(ifInstr *ssa.If, addr *ssa.FreeVar) (*ssa.BasicBlock, bool) {
+	cond, ok := ifInstr.Cond.(*ssa.BinOp)
+	if !ok || (cond.Op != token.EQL && cond.Op != token.NEQ) {
+		return nil, false
+	}
+	if !(isLoadOf(cond.X, addr) && isNil(cond.Y)) && !(isLoadOf(cond.Y, addr) && isNil(cond.X)) {
+		return nil, false
+	}
+	succs := ifInstr.Block().Succs
+	if cond.Op == token.NEQ {
+		// if err != nil { then: Succs[0] } else { Succs[1] }
+		return succs[0], true
+	}
+	return succs[1], true
+}
+
+func isLoadOf(val ssa.Value, addr ssa.Value) bool {
+	load, ok := val.(*ssa.UnOp)
+	return ok && load.Op == token.MUL && load.X == addr
+}
+
+func isNil(val ssa.Value) bool {
+	c, ok := val.(*ssa.Const)
+	return ok && c.IsNil()
+}
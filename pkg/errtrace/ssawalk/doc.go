@@ -0,0 +1,25 @@
+// Package ssawalk answers "where does this SSA value come from".
+//
+// Walk starts from a value (E.g. a returned error) and visits its sources recursively:
+// edges of Phi, values stored to variables and struct fields, values bound to captured variables,
+// values sent to channels and stored to maps, operands of conversions, and called functions.
+// Optionally it descends into values returned by static callees (see WithCallDescent),
+// and tracks variables of any type, not only error and func (see WithAnyType).
+//
+// DefaultVisitor is configured with options.
+// WithVisitFunction, WithVisitConst, WithVisitAlloc, WithVisitComplex, WithVisitRecover, WithVisitCall
+// and WithVisitCallInvoke are callbacks for the leaves of the walk.
+// On registers a callback for any value kind and decides how to handle the value with Policy:
+//
+//	visitor := ssawalk.NewDefaultVisitorWith(
+//		ssawalk.On(func(param *ssa.Parameter, path ssawalk.Path) (ssawalk.Policy, error) {
+//			fmt.Println("parameter", param.Name(), "via", path)
+//			return ssawalk.PolicyStop, nil
+//		}),
+//		ssawalk.WithPolicy[*ssa.Global](ssawalk.PolicyError),
+//	)
+//	err := ssawalk.Walk(visitor, value)
+//
+// Values whose sources can't be tracked result in UnsupportedError instead of panic,
+// callers should treat such values conservatively.
+package ssawalk
@@ -0,0 +1,25 @@
+package ssawalk
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// UnsupportedError is returned when walk reaches SSA value that can't be analyzed.
+// Analyzers should catch it per function, treat the function conservatively and continue.
+type UnsupportedError struct {
+	Value  ssa.Value
+	Reason string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s: %s (%T)", e.Reason, e.Value.Name(), e.Value)
+}
+
+// IsUnsupported returns true if err is UnsupportedError.
+func IsUnsupported(err error) bool {
+	var unsupported *UnsupportedError
+	return errors.As(err, &unsupported)
+}
@@ -0,0 +1,282 @@
+package ssawalk
+
+import (
+	"go/types"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/gostaticanalysis/analysisutil"
+)
+
+// This file contains tracking of struct fields and captured variables which hold errors or funcs.
+/*
+	type Server struct {
+		handlerFunc func(ctx context.Context) error
+	}
+
+	func NewServer() *Server {
+		return &Server{handlerFunc: handle}  // store to handlerFunc
+	}
+
+	func (s *Server) Hello(ctx context.Context) error {
+		return s.handlerFunc(ctx)  // load from handlerFunc, its source is handle
+	}
+*/
+
+// FieldStoredValues returns all values stored to the struct field read by val in the package of val.
+// val must be *ssa.FieldAddr or *ssa.Field.
+// ok is false if the field can't be tracked. E.g. the field is exported (other packages can store to it),
+// the field is neither error nor func, or the address of the field escapes.
+// Stores are indexed once per package while IndexFields is in effect, see fieldIndex.
+func FieldStoredValues(val ssa.Value) (values []ssa.Value, ok bool) {
+	structType, index, ok := fieldOf(val)
+	if !ok {
+		return nil, false
+	}
+	field := structType.Field(index)
+	if field.Exported() || !isTrackableType(field.Type()) {
+		return nil, false
+	}
+	fn := val.Parent()
+	if fn == nil || fn.Pkg == nil {
+		return nil, false
+	}
+	stores := packageFieldIndex(fn.Pkg).lookup(structType, index)
+	if stores == nil {
+		// never stored in the package. E.g. zero value.
+		return nil, true
+	}
+	return stores.values, stores.ok
+}
+
+// fieldStores is values stored to a struct field.
+type fieldStores struct {
+	values []ssa.Value
+	ok     bool // false if the address of the field escapes.
+}
+
+// fieldIndex holds values stored to trackable fields (See FieldStoredValues) of each struct type in a package.
+// Struct types are keyed by types.Identical like the field is read.
+type fieldIndex struct {
+	structs typeutil.Map // *types.Struct -> map[int]*fieldStores (field index)
+}
+
+// fieldIndexes holds fieldIndex of packages of each *ssa.Program while passes on the program run. See IndexFields.
+var fieldIndexes = struct {
+	sync.Mutex
+	programs map[*ssa.Program]*programFieldIndex
+}{programs: make(map[*ssa.Program]*programFieldIndex)}
+
+// programFieldIndex holds fieldIndex of packages in a program, shared by passes running on the program.
+type programFieldIndex struct {
+	refs     int
+	packages sync.Map // *ssa.Package -> *fieldIndex
+}
+
+// IndexFields caches stores to struct fields (See FieldStoredValues) of packages in prog until release is called.
+// Analyzers call it at the beginning of a pass and release it at the end of the pass.
+//
+//	defer ssawalk.IndexFields(ssaData.Pkg.Prog)()
+//
+// Passes running concurrently on the same program (E.g. all analyzers of rpcguard) share the index,
+// and it is dropped when the last pass releases it.
+// Without it, FieldStoredValues scans the package on every call.
+func IndexFields(prog *ssa.Program) (release func()) {
+	fieldIndexes.Lock()
+	defer fieldIndexes.Unlock()
+	idx := fieldIndexes.programs[prog]
+	if idx == nil {
+		idx = &programFieldIndex{}
+		fieldIndexes.programs[prog] = idx
+	}
+	idx.refs++
+	return sync.OnceFunc(func() {
+		fieldIndexes.Lock()
+		defer fieldIndexes.Unlock()
+		idx.refs--
+		if idx.refs == 0 {
+			delete(fieldIndexes.programs, prog)
+		}
+	})
+}
+
+func packageFieldIndex(pkg *ssa.Package) *fieldIndex {
+	fieldIndexes.Lock()
+	programIdx := fieldIndexes.programs[pkg.Prog]
+	fieldIndexes.Unlock()
+	if programIdx == nil {
+		return buildFieldIndex(pkg)
+	}
+	if idx, ok := programIdx.packages.Load(pkg); ok {
+		return idx.(*fieldIndex) //nolint:forcetypeassert // only *fieldIndex is stored.
+	}
+	idx, _ := programIdx.packages.LoadOrStore(pkg, buildFieldIndex(pkg))
+	return idx.(*fieldIndex) //nolint:forcetypeassert // only *fieldIndex is stored.
+}
+
+// buildFieldIndex scans every instruction of pkg once and indexes stores to trackable fields.
+func buildFieldIndex(pkg *ssa.Package) *fieldIndex {
+	idx := &fieldIndex{}
+	for _, pkgFunc := range PackageFuncs(pkg) {
+		for _, block := range pkgFunc.Blocks {
+			for _, instr := range block.Instrs {
+				fieldAddr, ok := instr.(*ssa.FieldAddr)
+				if !ok {
+					continue
+				}
+				structType, index, ok := fieldOf(fieldAddr)
+				if !ok {
+					continue
+				}
+				field := structType.Field(index)
+				if field.Exported() || !isTrackableType(field.Type()) {
+					continue
+				}
+				stores := idx.add(structType, index)
+				if !stores.ok {
+					continue
+				}
+				stored, ok := storedValues(fieldAddr)
+				if !ok {
+					stores.ok = false
+					stores.values = nil
+					continue
+				}
+				stores.values = append(stores.values, stored...)
+			}
+		}
+	}
+	return idx
+}
+
+// add returns fieldStores of the field, creating it if it doesn't exist.
+func (idx *fieldIndex) add(structType *types.Struct, index int) *fieldStores {
+	fields, _ := idx.structs.At(structType).(map[int]*fieldStores)
+	if fields == nil {
+		fields = make(map[int]*fieldStores)
+		idx.structs.Set(structType, fields)
+	}
+	if fields[index] == nil {
+		fields[index] = &fieldStores{ok: true}
+	}
+	return fields[index]
+}
+
+// lookup returns fieldStores of the field, or nil if the field is never stored.
+func (idx *fieldIndex) lookup(structType *types.Struct, index int) *fieldStores {
+	fields, _ := idx.structs.At(structType).(map[int]*fieldStores)
+	return fields[index]
+}
+
+// fieldOf returns struct type and field index read by val.
+func fieldOf(val ssa.Value) (*types.Struct, int, bool) {
+	var typ types.Type
+	var index int
+	switch val := val.(type) {
+	case *ssa.FieldAddr:
+		ptr, ok := val.X.Type().Underlying().(*types.Pointer)
+		if !ok {
+			return nil, 0, false
+		}
+		typ, index = ptr.Elem(), val.Field
+	case *ssa.Field:
+		typ, index = val.X.Type(), val.Field
+	default:
+		return nil, 0, false
+	}
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok || index >= structType.NumFields() {
+		return nil, 0, false
+	}
+	return structType, index, true
+}
+
+// FreeVarBindings returns values bound to freeVar by MakeClosure in the enclosing function.
+// ok is false if freeVar is neither error nor func (nor pointer to them), or the binding can't be found.
+func FreeVarBindings(freeVar *ssa.FreeVar) (values []ssa.Value, ok bool) {
+	typ := freeVar.Type()
+	if ptr, isPtr := typ.Underlying().(*types.Pointer); isPtr {
+		// captured by reference.
+		typ = ptr.Elem()
+	}
+	if !isTrackableType(typ) {
+		return nil, false
+	}
+	return closureBindings(freeVar)
+}
+
+// freeVarBindings is FreeVarBindings that also tracks freeVar of any type if anyType is true. See WithAnyType.
+func freeVarBindings(freeVar *ssa.FreeVar, anyType bool) ([]ssa.Value, bool) {
+	if anyType {
+		return closureBindings(freeVar)
+	}
+	return FreeVarBindings(freeVar)
+}
+
+// closureBindings returns values bound to freeVar by MakeClosure in the enclosing function regardless of its type.
+func closureBindings(freeVar *ssa.FreeVar) (values []ssa.Value, ok bool) {
+	fn := freeVar.Parent()
+	index := slices.Index(fn.FreeVars, freeVar)
+	if index < 0 || fn.Parent() == nil {
+		return nil, false
+	}
+	for _, block := range fn.Parent().Blocks {
+		for _, instr := range block.Instrs {
+			closure, ok := instr.(*ssa.MakeClosure)
+			if !ok || closure.Fn != fn || index >= len(closure.Bindings) {
+				continue
+			}
+			values = append(values, closure.Bindings[index])
+		}
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values, true
+}
+
+// isTrackableType returns true if typ is error interface or func.
+func isTrackableType(typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Signature); ok {
+		return true
+	}
+	return types.IsInterface(typ) && analysisutil.ImplementsError(typ)
+}
+
+// PackageFuncs returns functions, methods and their closures defined in pkg.
+func PackageFuncs(pkg *ssa.Package) []*ssa.Function {
+	var funcs []*ssa.Function
+	seen := make(map[*ssa.Function]struct{})
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		if fn == nil {
+			return
+		}
+		if _, ok := seen[fn]; ok {
+			return
+		}
+		seen[fn] = struct{}{}
+		funcs = append(funcs, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	for _, member := range pkg.Members {
+		switch member := member.(type) {
+		case *ssa.Function:
+			add(member)
+		case *ssa.Type:
+			named, ok := member.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			for method := range named.Methods() {
+				add(pkg.Prog.FuncValue(method))
+			}
+		}
+	}
+	return funcs
+}
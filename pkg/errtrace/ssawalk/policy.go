@@ -0,0 +1,117 @@
+package ssawalk
+
+import (
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Path is a chain of values from the root (the value given to Walk) to the visited value.
+// The last element is the visited value. Path is reused by Walk, copy it to retain.
+type Path []ssa.Value
+
+// Root returns the value given to Walk.
+func (p Path) Root() ssa.Value {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[0]
+}
+
+// Leaf returns the visited value.
+func (p Path) Leaf() ssa.Value {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[len(p)-1]
+}
+
+func (p Path) String() string {
+	names := make([]string, len(p))
+	for i, value := range p {
+		names[i] = value.Name()
+	}
+	return strings.Join(names, " <- ")
+}
+
+// Policy decides how DefaultVisitor handles a value.
+type Policy int
+
+const (
+	// PolicyDefault leaves the value to the next handler, or to the default behavior of DefaultVisitor.
+	PolicyDefault Policy = iota
+	// PolicyDescend walks sources of the value. See Sources.
+	// Walk returns UnsupportedError if sources of the value can't be tracked.
+	PolicyDescend
+	// PolicyStop stops walking at the value.
+	PolicyStop
+	// PolicyError aborts Walk with UnsupportedError.
+	PolicyError
+)
+
+// handler is called for every visited value.
+// matched is false if the value is not the kind the handler is registered for.
+type handler func(val ssa.Value, path Path) (policy Policy, matched bool, err error)
+
+type handlerOption handler
+
+func (f handlerOption) apply(opts *options) {
+	opts.handlers = append(opts.handlers, handler(f))
+}
+
+// On registers f for values of kind T. T is a concrete value type (E.g. *ssa.Parameter)
+// or an interface (E.g. ssa.Value for every value, ssa.Instruction for values defined by instructions).
+// Handlers are called in registration order before the default behavior,
+// the first handler returning a policy other than PolicyDefault decides how to handle the value.
+//
+//nolint:ireturn // for Uber option pattern.
+func On[T ssa.Value](f func(val T, path Path) (Policy, error)) Option {
+	return handlerOption(func(val ssa.Value, path Path) (Policy, bool, error) {
+		typed, ok := val.(T)
+		if !ok {
+			return PolicyDefault, false, nil
+		}
+		policy, err := f(typed, path)
+		return policy, true, err
+	})
+}
+
+// WithPolicy handles values of kind T with policy.
+// E.g. WithPolicy[*ssa.Global](PolicyStop) stops at globals without calling other callbacks.
+//
+//nolint:ireturn // for Uber option pattern.
+func WithPolicy[T ssa.Value](policy Policy) Option {
+	return On(func(_ T, _ Path) (Policy, error) {
+		return policy, nil
+	})
+}
+
+type callDescentOption bool
+
+func (f callDescentOption) apply(opts *options) {
+	opts.callDescent = bool(f)
+}
+
+// WithCallDescent makes Walk descend into return values of static calls instead of the called function.
+// E.g. for `t1 = foo()`, Walk visits values returned by foo.
+// Calls to functions without body (E.g. external functions) are walked as before.
+//
+//nolint:ireturn // for Uber option pattern.
+func WithCallDescent() Option {
+	return callDescentOption(true)
+}
+
+type anyTypeOption bool
+
+func (f anyTypeOption) apply(opts *options) {
+	opts.anyType = bool(f)
+}
+
+// WithAnyType makes Walk track variables and captured variables of any type, not only error and func.
+// E.g. context.Context reassigned after captured by closure.
+// Struct fields, channels and maps are still tracked only for error and func.
+//
+//nolint:ireturn // for Uber option pattern.
+func WithAnyType() Option {
+	return anyTypeOption(true)
+}
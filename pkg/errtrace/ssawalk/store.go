@@ -0,0 +1,125 @@
+package ssawalk
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// StoredValues returns all values stored to alloc if alloc is a variable of error interface or func type.
+// It follows stores in closures that capture alloc.
+// E.g. named result err written in defer.
+/*
+	func Foo() (err error) {   // t0 = new error (err)
+		defer func() {
+			err = errors.New("in defer")  // *err = t1 in Foo$1
+		}()
+		return nil  // *t0 = nil:error
+	}
+*/
+// ok is false if alloc is neither error interface nor func, or alloc escapes (E.g. passed to other function), then stores can't be tracked.
+func StoredValues(alloc *ssa.Alloc) (values []ssa.Value, ok bool) {
+	ptr, isPtr := alloc.Type().Underlying().(*types.Pointer)
+	if !isPtr || !isTrackableType(ptr.Elem()) {
+		return nil, false
+	}
+	if isResultSlot(alloc) && hasWrappingDefer(alloc) {
+		// Every return passes through the deferred closure that overwrites non-nil err.
+		// Thus, only stores in closures are the source of returned error.
+		return closureStoredValues(alloc)
+	}
+	return storedValues(alloc)
+}
+
+// allocStoredValues is StoredValues that also tracks alloc of any type if anyType is true. See WithAnyType.
+func allocStoredValues(alloc *ssa.Alloc, anyType bool) ([]ssa.Value, bool) {
+	if values, ok := StoredValues(alloc); ok || !anyType {
+		return values, ok
+	}
+	return storedValues(alloc)
+}
+
+// closureStoredValues returns values stored to alloc in closures that capture alloc.
+// Stores in the function itself are ignored.
+func closureStoredValues(alloc *ssa.Alloc) ([]ssa.Value, bool) {
+	var values []ssa.Value
+	for _, instr := range *alloc.Referrers() {
+		if _, ok := instr.(*ssa.MakeClosure); !ok {
+			continue
+		}
+		closureValues, ok := boundStoredValues(instr.(*ssa.MakeClosure), alloc)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, closureValues...)
+	}
+	return values, true
+}
+
+// storedValues returns values stored to addr which is Alloc, FieldAddr or FreeVar bound to Alloc.
+func storedValues(addr ssa.Value) ([]ssa.Value, bool) {
+	refs := addr.Referrers()
+	if refs == nil {
+		return nil, true
+	}
+	var values []ssa.Value
+	for _, instr := range *refs {
+		switch instr := instr.(type) {
+		case *ssa.Store:
+			if instr.Addr != addr {
+				// address itself is stored to somewhere.
+				return nil, false
+			}
+			values = append(values, instr.Val)
+		case *ssa.UnOp:
+			// load
+			continue
+		case *ssa.DebugRef:
+			continue
+		case *ssa.MakeClosure:
+			closureValues, ok := boundStoredValues(instr, addr)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, closureValues...)
+		default:
+			// E.g. passed to function, we can't track stores.
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// boundStoredValues returns values stored to free variables of closure that are bound to addr.
+func boundStoredValues(closure *ssa.MakeClosure, addr ssa.Value) ([]ssa.Value, bool) {
+	var values []ssa.Value
+	for _, freeVar := range boundFreeVars(closure, addr) {
+		if freeVar == nil {
+			return nil, false
+		}
+		closureValues, ok := storedValues(freeVar)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, closureValues...)
+	}
+	return values, true
+}
+
+// boundFreeVars returns free variables of closure that are bound to addr.
+// nil is contained if the free variable can't be found.
+func boundFreeVars(closure *ssa.MakeClosure, addr ssa.Value) []*ssa.FreeVar {
+	fn, ok := closure.Fn.(*ssa.Function)
+	var freeVars []*ssa.FreeVar
+	for i, binding := range closure.Bindings {
+		if binding != addr {
+			continue
+		}
+		if !ok || i >= len(fn.FreeVars) {
+			freeVars = append(freeVars, nil)
+			continue
+		}
+		freeVars = append(freeVars, fn.FreeVars[i])
+	}
+	return freeVars
+}
@@ -1,28 +1,66 @@
 package ssawalk
 
 import (
-	"fmt"
-
 	"golang.org/x/tools/go/ssa"
 )
 
+// Visitor visits ssa.Value.
+// If the result visitor is not nil, Walk visits sources of the value with the result visitor.
 type Visitor interface {
 	Visit(value ssa.Value) (Visitor, error)
 }
 
+// PathVisitor is a Visitor that receives the path from the root to the visited value.
+// If visitor implements PathVisitor, Walk calls VisitPath instead of Visit.
+type PathVisitor interface {
+	Visitor
+	VisitPath(value ssa.Value, path Path) (Visitor, error)
+}
+
+// callDescender is implemented by visitors that descend into return values of static calls.
+type callDescender interface {
+	descendsIntoCalls() bool
+}
+
+// anyTypeTracker is implemented by visitors that track variables of any type. See WithAnyType.
+type anyTypeTracker interface {
+	tracksAnyType() bool
+}
+
+// Walk visits val and its sources recursively in depth-first order.
+// Each value is visited at most once.
 func Walk(visitor Visitor, val ssa.Value) error {
-	visited := make(valueSet)
-	return walk(visitor, val, visited)
+	w := &walker{
+		visited:     make(valueSet),
+		callResults: make(map[callResult]struct{}),
+	}
+	return w.walk(visitor, val)
+}
+
+// callResult is a result of a call, it is used to descend into the same call with different result index.
+type callResult struct {
+	call  *ssa.Call
+	index int
+}
+
+type walker struct {
+	visited     valueSet
+	callResults map[callResult]struct{}
+	path        Path
 }
 
-//nolint:gocognit,gocyclo,cyclop // walk visit pattern
-func walk(visitor Visitor, val ssa.Value, visited valueSet) error {
-	if visited.includes(val) {
+func (w *walker) walk(visitor Visitor, val ssa.Value) error {
+	if w.visited.includes(val) {
 		return nil
 	}
-	visited.add(val)
+	w.visited.add(val)
 
-	visitor, err := visitor.Visit(val)
+	w.path = append(w.path, val)
+	defer func() {
+		w.path = w.path[:len(w.path)-1]
+	}()
+
+	visitor, err := w.visit(visitor, val)
 	if err != nil {
 		return err
 	}
@@ -30,61 +68,186 @@ func walk(visitor Visitor, val ssa.Value, visited valueSet) error {
 		return nil
 	}
 
-	switch val := val.(type) {
-	case *ssa.Function, *ssa.Const, *ssa.Alloc:
-		panic(fmt.Sprintf("unexpected: visitor should return nil visitor when reach %T (terminal)", val))
-	case *ssa.FreeVar, *ssa.Parameter, *ssa.Global, *ssa.MakeMap, *ssa.MakeChan, *ssa.MakeSlice,
-		*ssa.Slice, *ssa.FieldAddr, *ssa.Field, *ssa.IndexAddr, *ssa.Index, *ssa.Lookup, *ssa.Select, *ssa.Range, *ssa.Next:
-		panic(fmt.Sprintf("unexpected: visitor should return nil visitor when reach %T (too complex)", val))
-	case *ssa.Builtin, *ssa.BinOp, *ssa.Convert, *ssa.MultiConvert:
-		panic(fmt.Sprintf("unexpected: must not reach %T", val))
-	case *ssa.Phi:
-		for _, edge := range val.Edges {
-			if err := walk(visitor, edge, visited); err != nil {
-				return err
+	if descendsIntoCalls(visitor) {
+		switch val := val.(type) {
+		case *ssa.Extract:
+			if call, ok := val.Tuple.(*ssa.Call); ok && !call.Call.IsInvoke() {
+				return w.walkCallResult(visitor, call, val.Index)
+			}
+		case *ssa.Call:
+			if !val.Call.IsInvoke() {
+				w.callResults[callResult{call: val, index: 0}] = struct{}{}
+				return w.walkReturns(visitor, val, 0)
 			}
 		}
-	case *ssa.Call:
-		if val.Call.IsInvoke() {
-			panic(fmt.Sprintf("unexpected: visitor should return nil visitor when reach invoke mode Call: %s", val.String()))
-		}
-		if err := walk(visitor, val.Call.Value, visited); err != nil {
+	}
+
+	values, err := sources(val, tracksAnyType(visitor))
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := w.walk(visitor, value); err != nil {
 			return err
 		}
-	case *ssa.Extract:
-		if err := walk(visitor, val.Tuple, visited); err != nil {
-			return err
+	}
+	return nil
+}
+
+//nolint:ireturn // for Visitor pattern.
+func (w *walker) visit(visitor Visitor, val ssa.Value) (Visitor, error) {
+	if pathVisitor, ok := visitor.(PathVisitor); ok {
+		return pathVisitor.VisitPath(val, w.path)
+	}
+	return visitor.Visit(val)
+}
+
+// walkCallResult visits call and descends into its index-th result.
+// call is visited once per index, since Extract of the different index has the different sources.
+func (w *walker) walkCallResult(visitor Visitor, call *ssa.Call, index int) error {
+	key := callResult{call: call, index: index}
+	if _, ok := w.callResults[key]; ok {
+		return nil
+	}
+	w.callResults[key] = struct{}{}
+	w.visited.add(call)
+
+	w.path = append(w.path, call)
+	defer func() {
+		w.path = w.path[:len(w.path)-1]
+	}()
+
+	visitor, err := w.visit(visitor, call)
+	if err != nil {
+		return err
+	}
+	if visitor == nil {
+		return nil
+	}
+	return w.walkReturns(visitor, call, index)
+}
+
+// walkReturns walks index-th values returned by callee of call.
+// If callee is unknown (dynamic call) or has no body (external function), it walks the called value instead.
+func (w *walker) walkReturns(visitor Visitor, call *ssa.Call, index int) error {
+	callee := call.Call.StaticCallee()
+	if callee == nil || len(callee.Blocks) == 0 {
+		return w.walk(visitor, call.Call.Value)
+	}
+	for _, block := range callee.Blocks {
+		if len(block.Instrs) == 0 {
+			continue
 		}
-	case *ssa.MakeClosure:
-		if err := walk(visitor, val.Fn, visited); err != nil {
-			return err
+		rtn, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return)
+		if !ok || index >= len(rtn.Results) {
+			continue
 		}
-	case *ssa.UnOp:
-		if err := walk(visitor, val.X, visited); err != nil {
+		if err := w.walk(visitor, rtn.Results[index]); err != nil {
 			return err
 		}
-	case *ssa.ChangeType:
-		if err := walk(visitor, val.X, visited); err != nil {
-			return err
+	}
+	return nil
+}
+
+func descendsIntoCalls(visitor Visitor) bool {
+	descender, ok := visitor.(callDescender)
+	return ok && descender.descendsIntoCalls()
+}
+
+func tracksAnyType(visitor Visitor) bool {
+	tracker, ok := visitor.(anyTypeTracker)
+	return ok && tracker.tracksAnyType()
+}
+
+// Sources returns values that are direct sources of val.
+// E.g. edges of Phi, values stored to Alloc, values sent to channel, X of ChangeType.
+// Terminal values (Function, Const) have no sources.
+// It returns UnsupportedError if sources of val can't be tracked (E.g. Parameter, Global, invoke mode Call).
+func Sources(val ssa.Value) ([]ssa.Value, error) {
+	return sources(val, false)
+}
+
+// sources is Sources that also tracks variables of any type if anyType is true. See WithAnyType.
+//
+//nolint:gocyclo,cyclop // one case per value kind.
+func sources(val ssa.Value, anyType bool) ([]ssa.Value, error) {
+	switch val := val.(type) {
+	case *ssa.Function, *ssa.Const:
+		return nil, nil
+	case *ssa.Alloc:
+		values, ok := allocStoredValues(val, anyType)
+		if !ok {
+			return nil, &UnsupportedError{Value: val, Reason: "stores to untrackable alloc"}
 		}
-	case *ssa.ChangeInterface:
-		if err := walk(visitor, val.X, visited); err != nil {
-			return err
+		return values, nil
+	case *ssa.FreeVar:
+		values, ok := freeVarBindings(val, anyType)
+		if !ok {
+			return nil, &UnsupportedError{Value: val, Reason: "bindings of untrackable free variable"}
 		}
-	case *ssa.SliceToArrayPointer:
-		if err := walk(visitor, val.X, visited); err != nil {
-			return err
+		return values, nil
+	case *ssa.FieldAddr, *ssa.Field:
+		values, ok := FieldStoredValues(val)
+		if !ok {
+			return nil, &UnsupportedError{Value: val, Reason: "stores to untrackable field"}
 		}
-	case *ssa.MakeInterface:
-		if err := walk(visitor, val.X, visited); err != nil {
-			return err
+		return values, nil
+	case *ssa.Parameter, *ssa.Global, *ssa.MakeMap, *ssa.MakeChan, *ssa.MakeSlice,
+		*ssa.Slice, *ssa.IndexAddr, *ssa.Index, *ssa.Select, *ssa.Range, *ssa.Next,
+		*ssa.BinOp, *ssa.Convert, *ssa.MultiConvert:
+		return nil, &UnsupportedError{Value: val, Reason: "sources of too complex value"}
+	case *ssa.Builtin:
+		if isRecover(val) {
+			return nil, &UnsupportedError{Value: val, Reason: reasonRecovered}
 		}
-	case *ssa.TypeAssert:
-		if err := walk(visitor, val.X, visited); err != nil {
-			return err
+		return nil, &UnsupportedError{Value: val, Reason: "sources of too complex value"}
+	case *ssa.UnOp, *ssa.Lookup, *ssa.Extract:
+		if !isReceived(val) {
+			return operand(val)
+		}
+		values, ok := ReceivedValues(val)
+		if !ok {
+			return nil, &UnsupportedError{Value: val, Reason: "sends (stores) to untrackable channel (map)"}
+		}
+		return values, nil
+	case *ssa.Phi:
+		return val.Edges, nil
+	case *ssa.Call:
+		if val.Call.IsInvoke() {
+			return nil, &UnsupportedError{Value: val, Reason: "sources of invoke mode call"}
 		}
-	default:
-		panic(fmt.Sprintf("unknown type %T", val))
+		return []ssa.Value{val.Call.Value}, nil
+	case *ssa.MakeClosure:
+		return []ssa.Value{val.Fn}, nil
+	case *ssa.ChangeType:
+		return []ssa.Value{val.X}, nil
+	case *ssa.ChangeInterface:
+		return []ssa.Value{val.X}, nil
+	case *ssa.SliceToArrayPointer:
+		return []ssa.Value{val.X}, nil
+	case *ssa.MakeInterface:
+		return []ssa.Value{val.X}, nil
+	case *ssa.TypeAssert:
+		return []ssa.Value{val.X}, nil
 	}
-	return nil
+	return nil, &UnsupportedError{Value: val, Reason: "unknown value"}
+}
+
+// reasonRecovered is the reason of UnsupportedError for the value returned by recover().
+const reasonRecovered = "recovered value"
+
+// isRecover returns true if builtin is recover, which returns the value passed to panic.
+func isRecover(builtin *ssa.Builtin) bool {
+	return builtin.Name() == "recover"
+}
+
+// operand returns operand of UnOp (E.g. load) or tuple of Extract.
+func operand(val ssa.Value) ([]ssa.Value, error) {
+	switch val := val.(type) {
+	case *ssa.UnOp:
+		return []ssa.Value{val.X}, nil
+	case *ssa.Extract:
+		return []ssa.Value{val.Tuple}, nil
+	}
+	return nil, &UnsupportedError{Value: val, Reason: "unknown value"}
 }
@@ -0,0 +1,396 @@
+package ssawalk_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+
+	"github.com/cloverrose/rpcguard/pkg/errtrace/ssawalk"
+)
+
+const src = `package p
+
+type myErr struct{}
+
+func (myErr) Error() string { return "" }
+
+func a() error { return myErr{} }
+
+func b() error { return nil }
+
+func phi(flag bool) error {
+	f := a
+	if flag {
+		f = b
+	}
+	return f()
+}
+
+func captured() error {
+	var err error
+	set := func() {
+		err = a()
+	}
+	set()
+	return err
+}
+
+func channel() error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a()
+	}()
+	return <-errCh
+}
+
+func lookup(key string) error {
+	errs := map[string]error{"a": a()}
+	errs["b"] = b()
+	return errs[key]
+}
+
+func param(err error) error {
+	return err
+}
+
+func escape() error {
+	errCh := make(chan error, 1)
+	send(errCh)
+	return <-errCh
+}
+
+func send(errCh chan error) {
+	errCh <- a()
+}
+
+func inner() error {
+	return b()
+}
+
+func outer() error {
+	return inner()
+}
+
+func pair() (int, error) {
+	return 1, b()
+}
+
+func usePair() error {
+	_, err := pair()
+	return err
+}
+
+type server struct {
+	handler func() error
+	leaked  func() error
+}
+
+func newServer(flag bool) *server {
+	s := &server{handler: a}
+	if flag {
+		s.handler = b
+	}
+	return s
+}
+
+func (s *server) field() error {
+	return s.handler()
+}
+
+func leak(s *server) *func() error {
+	return &s.leaked
+}
+
+func (s *server) escapedField() error {
+	return s.leaked()
+}
+
+func recovered() error {
+	return recover().(error)
+}
+
+func one() int { return 1 }
+
+func capturedInt() int {
+	var n int
+	set := func() {
+		n = one()
+	}
+	set()
+	return n
+}
+`
+
+func buildPackage(t *testing.T) *ssa.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, _, err := ssautil.BuildPackage(
+		&types.Config{Importer: importer.Default()}, fset, types.NewPackage("p", ""), []*ast.File{file}, ssa.SanityCheckFunctions,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// returned returns the last value returned by the function name.
+func returned(t *testing.T, pkg *ssa.Package, name string) ssa.Value {
+	t.Helper()
+	fn := pkg.Func(name)
+	if fn == nil {
+		fn = pkg.Prog.LookupMethod(types.NewPointer(pkg.Type("server").Type()), pkg.Pkg, name)
+	}
+	if fn == nil {
+		t.Fatalf("func %s not found", name)
+	}
+	for _, block := range fn.Blocks {
+		if rtn, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return); ok {
+			return rtn.Results[len(rtn.Results)-1]
+		}
+	}
+	t.Fatalf("return not found in %s", name)
+	return nil
+}
+
+// leaves is a result of walk.
+type leaves struct {
+	Funcs   []string // visited functions.
+	Complex []string // visited values that can't be tracked.
+}
+
+func walk(t *testing.T, val ssa.Value, opts ...ssawalk.Option) (leaves, error) {
+	t.Helper()
+	var got leaves
+	opts = append([]ssawalk.Option{
+		ssawalk.WithVisitFunction(func(val *ssa.Function) error {
+			got.Funcs = append(got.Funcs, val.Name())
+			return nil
+		}),
+		ssawalk.WithVisitComplex(func(val ssa.Value) error {
+			got.Complex = append(got.Complex, val.Name())
+			return nil
+		}),
+	}, opts...)
+	err := ssawalk.Walk(ssawalk.NewDefaultVisitorWith(opts...), val)
+	slices.Sort(got.Funcs)
+	slices.Sort(got.Complex)
+	return got, err
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+	t.Cleanup(ssawalk.IndexFields(pkg.Prog))
+	tests := []struct {
+		name string
+		want leaves
+	}{
+		{name: "phi", want: leaves{Funcs: []string{"a", "b"}}},
+		{name: "captured", want: leaves{Funcs: []string{"a"}}},
+		{name: "channel", want: leaves{Funcs: []string{"a"}}},
+		{name: "lookup", want: leaves{Funcs: []string{"a", "b"}}},
+		{name: "param", want: leaves{Complex: []string{"err"}}},
+		{name: "escape", want: leaves{Complex: []string{"t2"}}},
+		{name: "outer", want: leaves{Funcs: []string{"inner"}}},
+		{name: "field", want: leaves{Funcs: []string{"a", "b"}}},
+		{name: "escapedField", want: leaves{Complex: []string{"t0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := walk(t, returned(t, pkg, tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("walk mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWalk_Policy(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+		got, err := walk(t, returned(t, pkg, "phi"), ssawalk.WithPolicy[*ssa.Phi](ssawalk.PolicyStop))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(leaves{}, got); diff != "" {
+			t.Errorf("walk mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		_, err := walk(t, returned(t, pkg, "param"), ssawalk.WithPolicy[*ssa.Parameter](ssawalk.PolicyError))
+		if !ssawalk.IsUnsupported(err) {
+			t.Errorf("want UnsupportedError, got %v", err)
+		}
+	})
+
+	t.Run("descend untrackable", func(t *testing.T) {
+		t.Parallel()
+		_, err := walk(t, returned(t, pkg, "param"), ssawalk.WithPolicy[*ssa.Parameter](ssawalk.PolicyDescend))
+		var unsupported *ssawalk.UnsupportedError
+		if !errors.As(err, &unsupported) || unsupported.Value.Name() != "err" {
+			t.Errorf("want UnsupportedError for err, got %v", err)
+		}
+	})
+
+	t.Run("first handler wins", func(t *testing.T) {
+		t.Parallel()
+		var kinds []string
+		got, err := walk(t, returned(t, pkg, "phi"),
+			ssawalk.On(func(val ssa.Value, _ ssawalk.Path) (ssawalk.Policy, error) {
+				if _, ok := val.(*ssa.Function); ok {
+					kinds = append(kinds, val.Name())
+				}
+				return ssawalk.PolicyDefault, nil
+			}),
+			ssawalk.On(func(val *ssa.Function, _ ssawalk.Path) (ssawalk.Policy, error) {
+				if val.Name() == "b" {
+					return ssawalk.PolicyStop, nil
+				}
+				return ssawalk.PolicyDefault, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(kinds)
+		if diff := cmp.Diff([]string{"a", "b"}, kinds); diff != "" {
+			t.Errorf("handler mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(leaves{Funcs: []string{"a"}}, got); diff != "" {
+			t.Errorf("walk mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestWalk_Path(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+	var got []string
+	visitor := ssawalk.NewDefaultVisitorWith(
+		ssawalk.On(func(val *ssa.Function, path ssawalk.Path) (ssawalk.Policy, error) {
+			if path.Leaf() != val || path.Root() == nil {
+				t.Errorf("unexpected path %s for %s", path, val.Name())
+			}
+			got = append(got, path.String())
+			return ssawalk.PolicyStop, nil
+		}),
+	)
+	if err := ssawalk.Walk(visitor, returned(t, pkg, "channel")); err != nil {
+		t.Fatal(err)
+	}
+	// return <-errCh (t4) is traced to the value sent in closure: errCh <- a() (t1).
+	want := []string{"t4 <- t1 <- a"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("path mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalk_CallDescent(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+	tests := []struct {
+		name string
+		want []string
+	}{
+		// inner() (t0 in outer) <- b() (t0 in inner) <- nil in b
+		{name: "outer", want: []string{"t0 <- t0 <- nil:error"}},
+		// extract #1 (t2) <- pair() (t0 in usePair) <- b() (t0 in pair) <- nil in b, 1 in pair is not visited.
+		{name: "usePair", want: []string{"t2 <- t0 <- t0 <- nil:error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			visitor := ssawalk.NewDefaultVisitorWith(
+				ssawalk.WithCallDescent(),
+				ssawalk.On(func(_ *ssa.Const, path ssawalk.Path) (ssawalk.Policy, error) {
+					got = append(got, path.String())
+					return ssawalk.PolicyStop, nil
+				}),
+			)
+			if err := ssawalk.Walk(visitor, returned(t, pkg, tt.name)); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("const mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWalk_AnyType(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+	tests := []struct {
+		name string
+		opts []ssawalk.Option
+		want leaves
+	}{
+		// variable of int is not tracked by default.
+		{name: "default", want: leaves{}},
+		{name: "any type", opts: []ssawalk.Option{ssawalk.WithAnyType()}, want: leaves{Funcs: []string{"one"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := walk(t, returned(t, pkg, "capturedInt"), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("walk mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWalk_Recover(t *testing.T) {
+	t.Parallel()
+	pkg := buildPackage(t)
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		_, err := walk(t, returned(t, pkg, "recovered"))
+		var unsupported *ssawalk.UnsupportedError
+		if !errors.As(err, &unsupported) || unsupported.Reason != "recovered value" {
+			t.Errorf("want UnsupportedError for recovered value, got %v", err)
+		}
+	})
+
+	t.Run("visit recover", func(t *testing.T) {
+		t.Parallel()
+		var got []string
+		_, err := walk(t, returned(t, pkg, "recovered"), ssawalk.WithVisitRecover(func(val *ssa.Builtin) error {
+			got = append(got, val.Name())
+			return nil
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"recover"}, got); diff != "" {
+			t.Errorf("recover mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
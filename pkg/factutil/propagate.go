@@ -0,0 +1,77 @@
+package factutil
+
+import (
+	"log/slog"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+// Propagation propagates facts from callees to callers through strongly connected components of the call graph.
+// All funcs in the same SCC reach each other, so if one of them has the fact, all of them have the fact.
+type Propagation[T analysis.Fact] struct {
+	// Callees returns funcs called from fn whose facts are propagated to fn.
+	Callees func(fn *ssa.Function) []*ssa.Function
+	// Stops returns true if fn stops propagation. E.g. fn recovers panic. Optional.
+	Stops func(fn *ssa.Function) bool
+	// Derive returns the fact of fn from source, the fact that makes the SCC of fn marked.
+	// E.g. prepends fn to the call chain of source.
+	Derive func(fn *ssa.Function, source T) T
+}
+
+// Run propagates facts in sccs, which are sorted callees first (See graph.Decomposition).
+// Funcs defined in other packages are skipped, their facts are imported.
+func (p *Propagation[T]) Run(pass *analysis.Pass, log *slog.Logger, sccs [][]*ssa.Function, factWrapper *FactWrapper[T]) {
+	for _, scc := range sccs {
+		source, ok := p.findSource(pass, scc, factWrapper)
+		if !ok {
+			continue
+		}
+		for _, fn := range scc {
+			if !p.isLocal(pass, fn) {
+				continue
+			}
+			if _, ok := factWrapper.Import(fn); ok {
+				continue
+			}
+			log.Debug("propagate mark", logger.Attr(fn))
+			factWrapper.Export(fn, p.Derive(fn, source))
+		}
+	}
+}
+
+// findSource returns the fact that makes given scc marked.
+//
+//nolint:ireturn // Need to return interface for generics.
+func (p *Propagation[T]) findSource(pass *analysis.Pass, scc []*ssa.Function, factWrapper *FactWrapper[T]) (T, bool) {
+	for _, fn := range scc {
+		if !p.isLocal(pass, fn) {
+			// fn is defined in different package, the fact is imported via callers.
+			continue
+		}
+		if fact, ok := factWrapper.Import(fn); ok {
+			return fact, true
+		}
+		for _, callee := range p.Callees(fn) {
+			if slices.Contains(scc, callee) {
+				continue
+			}
+			if fact, ok := factWrapper.Import(callee); ok {
+				return fact, true
+			}
+		}
+	}
+	var t T
+	return t, false
+}
+
+// isLocal returns true if fn is defined in the package of pass and doesn't stop propagation.
+func (p *Propagation[T]) isLocal(pass *analysis.Pass, fn *ssa.Function) bool {
+	if fn.Pkg == nil || fn.Pkg.Pkg != pass.Pkg {
+		return false
+	}
+	return p.Stops == nil || !p.Stops(fn)
+}
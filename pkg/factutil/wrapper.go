@@ -11,6 +11,15 @@ import (
 type FactWrapper[T analysis.Fact] struct {
 	pass       *analysis.Pass
 	localFacts map[*ssa.Function]T
+	counts     Counts
+}
+
+// Counts is the number of calls of FactWrapper methods.
+type Counts struct {
+	Exported  int // facts exported to pass or local facts.
+	LocalHits int // Import found the fact in local facts.
+	Imported  int // Import found the fact in pass, e.g. exported by dependencies.
+	Misses    int // Import didn't find the fact.
 }
 
 func NewFactWrapper[T analysis.Fact](pass *analysis.Pass) *FactWrapper[T] {
@@ -24,6 +33,7 @@ func (f *FactWrapper[T]) Export(fn *ssa.Function, fact T) {
 	if fn == nil {
 		panic("fn == nil")
 	}
+	f.counts.Exported++
 	if fn.Pkg == nil || fn.Pkg.Pkg == nil || fn.Pkg.Pkg != f.pass.Pkg || fn.Object() == nil {
 		f.localFacts[fn] = fact
 	} else {
@@ -37,9 +47,11 @@ func (f *FactWrapper[T]) Import(fn *ssa.Function) (T, bool) {
 		panic("fn == nil")
 	}
 	if lf, ok := f.localFacts[fn]; ok {
+		f.counts.LocalHits++
 		return lf, true
 	}
 	if fn.Object() == nil {
+		f.counts.Misses++
 		var t T
 		return t, false
 	}
@@ -50,8 +62,15 @@ func (f *FactWrapper[T]) Import(fn *ssa.Function) (T, bool) {
 		panic(fmt.Sprintf("fact type %s is not T", factType))
 	}
 	if f.pass.ImportObjectFact(fn.Object(), factValue) {
+		f.counts.Imported++
 		return factValue, true
 	}
+	f.counts.Misses++
 	var t T
 	return t, false
 }
+
+// Counts returns the number of calls of Export and Import so far.
+func (f *FactWrapper[T]) Counts() Counts {
+	return f.counts
+}
@@ -1,65 +1,207 @@
 package filter
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
-	"slices"
 	"strings"
 )
 
+// Filter decides whether a value (package path or file name) is target or not.
+// A value is target if it matches some include pattern and doesn't match any negated or exclude pattern.
+//
+// Patterns are written as a list.
+//   - Comma separated: `a,b`. A comma in a pattern is escaped by backslash: `x{1\,3}`.
+//   - JSON array: `["a", "x{1,3}"]`.
+//
+// Each pattern is one of
+//   - Go package pattern: a pattern which contains "..." or starts with "./".
+//     "..." matches any string, and "x/..." matches x itself too (e.g. github.com/org/repo/...).
+//     "./..." and "./x" are relative to the modules given by WithModules.
+//   - Regexp: `re:` prefixed pattern or any other pattern (e.g. `.+_test\.go`).
+//
+// A pattern prefixed with "!" is negated, it excludes values matching the rest of the pattern.
 type Filter struct {
-	// package path regexp to include.
-	includes []*regexp.Regexp
+	// patterns to include.
+	includes []*pattern
 
-	// package path regexp to exclude.
-	excludes []*regexp.Regexp
+	// patterns to exclude. It includes negated patterns of includes.
+	excludes []*pattern
 }
 
-func New(includesStr, excludesStr string) (*Filter, error) {
+type pattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// Option is option of New.
+type Option func(*options)
+
+type options struct {
+	modules []string
+}
+
+// WithModules sets module paths used to resolve relative patterns like "./...".
+// Without modules, relative patterns match nothing.
+func WithModules(modules ...string) Option {
+	return func(o *options) {
+		o.modules = modules
+	}
+}
+
+func New(includesStr, excludesStr string, opts ...Option) (*Filter, error) {
 	if includesStr == "" {
 		return nil, errors.New("includesStr unspecified")
 	}
-
-	includes, err := parseRegexps(includesStr)
-	if err != nil {
-		return nil, fmt.Errorf("includesStr parse error: %v", err)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
 
+	f := &Filter{}
+	if err := f.add(includesStr, false, o.modules); err != nil {
+		return nil, fmt.Errorf("includesStr parse error: %w", err)
+	}
 	if excludesStr == "" {
-		return &Filter{
-			includes: includes,
-		}, nil
+		return f, nil
+	}
+	if err := f.add(excludesStr, true, o.modules); err != nil {
+		return nil, fmt.Errorf("excludesStr parse error: %w", err)
 	}
+	return f, nil
+}
 
-	excludes, err := parseRegexps(excludesStr)
-	if err != nil {
-		return nil, fmt.Errorf("excludesStr parse error: %v", err)
+// add parses list of patterns and appends them to f.
+func (f *Filter) add(input string, exclude bool, modules []string) error {
+	values := splitList(input)
+	for _, raw := range values {
+		value, negated := strings.CutPrefix(raw, "!")
+		re, err := compile(value, modules)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", raw, err)
+		}
+		ptn := &pattern{raw: raw, re: re}
+		if exclude || negated {
+			f.excludes = append(f.excludes, ptn)
+		} else {
+			f.includes = append(f.includes, ptn)
+		}
 	}
+	return nil
+}
 
-	return &Filter{
-		includes: includes,
-		excludes: excludes,
-	}, nil
+// splitList splits input into patterns.
+// input is JSON array of strings, or comma separated patterns where `\,` is a literal comma.
+// input that is not JSON array is comma separated even if it starts with `[`. E.g. `[a-z_]+_gen\.go`.
+func splitList(input string) []string {
+	var list []string
+	if err := json.Unmarshal([]byte(input), &list); err == nil {
+		return list
+	}
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(input); i++ {
+		switch {
+		case input[i] == '\\' && i+1 < len(input) && input[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case input[i] == ',':
+			values = append(values, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(input[i])
+		}
+	}
+	return append(values, current.String())
 }
 
-func parseRegexps(input string) ([]*regexp.Regexp, error) {
-	values := strings.Split(input, ",")
-	result := make([]*regexp.Regexp, len(values))
-	for i, ptn := range values {
-		v, err := regexp.Compile(ptn)
-		if err != nil {
-			return nil, fmt.Errorf("invalid pattern %q: %w", ptn, err)
+// compile compiles a pattern into regexp.
+func compile(value string, modules []string) (*regexp.Regexp, error) {
+	if re, ok := strings.CutPrefix(value, "re:"); ok {
+		return regexp.Compile(re)
+	}
+	if !isGoPattern(value) {
+		return regexp.Compile(value)
+	}
+	if !strings.HasPrefix(value, "./") && value != "." {
+		return regexp.MustCompile(`^` + goPatternRegexp(value) + `$`), nil
+	}
+	// relative pattern
+	rest := strings.TrimPrefix(strings.TrimPrefix(value, "."), "/")
+	alternatives := make([]string, 0, len(modules))
+	for _, module := range modules {
+		if rest == "" {
+			alternatives = append(alternatives, goPatternRegexp(module))
+		} else {
+			alternatives = append(alternatives, goPatternRegexp(module+"/"+rest))
 		}
-		result[i] = v
 	}
-	return result, nil
+	if len(alternatives) == 0 {
+		// never matches.
+		return regexp.MustCompile(`[^\s\S]`), nil
+	}
+	return regexp.MustCompile(`^(` + strings.Join(alternatives, "|") + `)$`), nil
+}
+
+func isGoPattern(value string) bool {
+	return strings.Contains(value, "...") || strings.HasPrefix(value, "./") || value == "."
+}
+
+// goPatternRegexp returns unanchored regexp of Go package pattern in the same way as the go command.
+// "..." matches any string, and trailing "/..." matches empty string too.
+func goPatternRegexp(value string) string {
+	re := regexp.QuoteMeta(value)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if rest, ok := strings.CutSuffix(re, `/.*`); ok {
+		re = rest + `(/.*)?`
+	}
+	return re
 }
 
 func (f *Filter) IsTarget(value string) bool {
-	return slices.ContainsFunc(f.includes, func(ptn *regexp.Regexp) bool {
-		return ptn.MatchString(value)
-	}) && !slices.ContainsFunc(f.excludes, func(ptn *regexp.Regexp) bool {
-		return ptn.MatchString(value)
-	})
+	return f.Explain(value).Target
+}
+
+// Explanation tells why a value is target or not.
+type Explanation struct {
+	Value  string
+	Target bool
+	// Include is the include pattern matched first. It is empty if no include pattern matches.
+	Include string
+	// Exclude is the negated or exclude pattern matched first. It is empty if no exclude pattern matches.
+	Exclude string
+}
+
+func (e Explanation) String() string {
+	switch {
+	case e.Include == "":
+		return fmt.Sprintf("%s is not included: no include pattern matches", e.Value)
+	case e.Exclude != "":
+		return fmt.Sprintf("%s is excluded by %q (included by %q)", e.Value, e.Exclude, e.Include)
+	default:
+		return fmt.Sprintf("%s is included by %q", e.Value, e.Include)
+	}
+}
+
+// Explain returns why value is target or not.
+func (f *Filter) Explain(value string) Explanation {
+	e := Explanation{Value: value}
+	if ptn := firstMatch(f.includes, value); ptn != nil {
+		e.Include = ptn.raw
+	}
+	if ptn := firstMatch(f.excludes, value); ptn != nil {
+		e.Exclude = ptn.raw
+	}
+	e.Target = e.Include != "" && e.Exclude == ""
+	return e
+}
+
+func firstMatch(patterns []*pattern, value string) *pattern {
+	for _, ptn := range patterns {
+		if ptn.re.MatchString(value) {
+			return ptn
+		}
+	}
+	return nil
 }
@@ -0,0 +1,167 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter_IsTarget(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		includes string
+		excludes string
+		modules  []string
+		want     map[string]bool
+	}{
+		{
+			name:     "regexp",
+			includes: `github.com/x/y/.*`,
+			want: map[string]bool{
+				"github.com/x/y/a": true,
+				"github.com/x/yz":  false,
+			},
+		},
+		{
+			name:     "go pattern",
+			includes: `github.com/x/y/...`,
+			want: map[string]bool{
+				"github.com/x/y":     true,
+				"github.com/x/y/a/b": true,
+				"github.com/x/yz":    false,
+				"github.com/xx/y":    false,
+			},
+		},
+		{
+			name:     "go pattern in the middle",
+			includes: `github.com/x/.../internal`,
+			want: map[string]bool{
+				"github.com/x/a/internal":   true,
+				"github.com/x/a/internalz":  false,
+				"github.com/x/a/internal/b": false,
+			},
+		},
+		{
+			name:     "relative pattern",
+			includes: `./...`,
+			modules:  []string{"example.com/api", "example.com/svc"},
+			want: map[string]bool{
+				"example.com/api":         true,
+				"example.com/svc/handler": true,
+				"example.com/svcx":        false,
+				"exampleXcom/api":         false,
+			},
+		},
+		{
+			name:     "relative pattern of a package",
+			includes: `./internal/...,.`,
+			modules:  []string{"example.com/svc"},
+			want: map[string]bool{
+				"example.com/svc":            true,
+				"example.com/svc/internal/a": true,
+				"example.com/svc/handler":    false,
+			},
+		},
+		{
+			name:     "relative pattern without modules",
+			includes: `./...`,
+			want: map[string]bool{
+				"":                false,
+				"example.com/svc": false,
+			},
+		},
+		{
+			name:     "negated pattern",
+			includes: `./...,!./internal/...`,
+			modules:  []string{"example.com/svc"},
+			want: map[string]bool{
+				"example.com/svc/handler":    true,
+				"example.com/svc/internal/a": false,
+			},
+		},
+		{
+			name:     "excludes",
+			includes: `github.com/x/y/...`,
+			excludes: `(.+/)?vendor(/.*)?$`,
+			want: map[string]bool{
+				"github.com/x/y/a":          true,
+				"github.com/x/y/vendor/a/b": false,
+			},
+		},
+		{
+			name:     "escaped comma",
+			includes: `re:^a{1\,2}$,b`,
+			want: map[string]bool{
+				"aa":  true,
+				"aaa": false,
+				"b":   true,
+			},
+		},
+		{
+			name:     "list",
+			includes: `["^a{1,2}$", "github.com/x/y/..."]`,
+			want: map[string]bool{
+				"aa":               true,
+				"aaa":              false,
+				"github.com/x/y/z": true,
+			},
+		},
+		{
+			name:     "regexp starting with character class",
+			includes: `[a-z_]+_gen\.go,b`,
+			want: map[string]bool{
+				"foo_gen.go": true,
+				"foo.go":     false,
+				"b":          true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := New(tt.includes, tt.excludes, WithModules(tt.modules...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]bool, len(tt.want))
+			for value := range tt.want {
+				got[value] = f.IsTarget(value)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("IsTarget() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilter_Explain(t *testing.T) {
+	t.Parallel()
+	f, err := New(`./...,!./internal/...`, `.*/mock`, WithModules("example.com/svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "example.com/svc/handler", want: `example.com/svc/handler is included by "./..."`},
+		{value: "example.com/svc/internal/a", want: `example.com/svc/internal/a is excluded by "!./internal/..." (included by "./...")`},
+		{value: "example.com/svc/mock", want: `example.com/svc/mock is excluded by ".*/mock" (included by "./...")`},
+		{value: "example.com/other", want: `example.com/other is not included: no include pattern matches`},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, f.Explain(tt.value).String()); diff != "" {
+			t.Errorf("Explain(%q) mismatch (-want +got):\n%s", tt.value, diff)
+		}
+	}
+}
+
+func TestNew_Error(t *testing.T) {
+	t.Parallel()
+	for _, includes := range []string{"", "re:(", `["a"`} {
+		if _, err := New(includes, ""); err == nil {
+			t.Errorf("New(%q) expected error", includes)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package filter
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// IsGenerated returns true if pos is in a file of pass which has the standard generated-code header.
+// i.e. a comment line "// Code generated ... DO NOT EDIT." before the package clause.
+// See https://go.dev/s/generatedcode
+func IsGenerated(pass *analysis.Pass, pos token.Pos) bool {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return ast.IsGenerated(file)
+		}
+	}
+	return false
+}
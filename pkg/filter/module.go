@@ -0,0 +1,275 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/cloverrose/rpcguard/pkg/modutil"
+)
+
+// TargetModules returns paths of the modules which relative patterns of includePackages are resolved against,
+// and which packages not in includePackages are told to belong to.
+// If includePackages is empty (default), it returns the main modules found by PassModules.
+// Otherwise includePackages is explicit, and it returns the module told by the driver (e.g. go vet).
+// PassModules is used only if the driver doesn't tell it (e.g. analysistest in GOPATH mode).
+func TargetModules(pass *analysis.Pass, includePackages string) ([]string, error) {
+	if pass.Module != nil && pass.Module.Path != "" {
+		switch {
+		case includePackages != "":
+			return []string{pass.Module.Path}, nil
+		case pass.Module.Version != "":
+			// dependency, only main modules have no version.
+			return nil, nil
+		case os.Getenv("GOWORK") == "off":
+			// main module, no go.work to read.
+			return []string{pass.Module.Path}, nil
+		}
+	}
+	return PassModules(pass)
+}
+
+// ForPass returns the filter of packages to analyze in pass, and the target modules (See TargetModules).
+// If includePackages is empty (default), packages of the main modules are included.
+// If the package is not in a main module then, the filter is nil and skipReason tells why the package is skipped.
+// Skipping a dependency is logged at debug level, skipping others (e.g. go.mod is not found) at warn level
+// since they are analyzed only by specifying includePackages.
+func ForPass(
+	pass *analysis.Pass, log *slog.Logger, includePackages, excludePackages string,
+) (packages *Filter, modules []string, skipReason string, err error) {
+	modules, err = TargetModules(pass, includePackages)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if includePackages == "" {
+		// default: all packages of the main modules.
+		if len(modules) == 0 {
+			if IsDependency(pass) {
+				log.Debug("skip package (not in main module)")
+			} else {
+				log.Warn("skip package (not in main module)")
+			}
+			return nil, nil, "package is not in main module", nil
+		}
+		includePackages = "./..."
+	}
+	packages, err = New(includePackages, excludePackages, WithModules(modules...))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return packages, modules, "", nil
+}
+
+// PassModules returns paths of the main modules of the package under analysis.
+// It returns nil if the package is not in a main module (e.g. dependency in GOMODCACHE, vendor, GOROOT).
+func PassModules(pass *analysis.Pass) ([]string, error) {
+	dir, ok := passDir(pass)
+	if !ok {
+		return nil, nil
+	}
+	return MainModules(dir)
+}
+
+// IsDependency returns true if the package under analysis is in GOROOT, GOMODCACHE or vendor directory,
+// i.e. it is not in a main module as expected.
+func IsDependency(pass *analysis.Pass) bool {
+	if pass.Module != nil && pass.Module.Path != "" {
+		return pass.Module.Version != ""
+	}
+	dir, ok := passDir(pass)
+	if !ok {
+		return false
+	}
+	if isDependencyDir(dir) {
+		return true
+	}
+	modDir, ok, err := modutil.FindUp(dir, "go.mod")
+	return err == nil && ok && isVendorDir(modDir, dir)
+}
+
+// passDir returns the directory of the package under analysis.
+func passDir(pass *analysis.Pass) (string, bool) {
+	if len(pass.Files) == 0 {
+		return "", false
+	}
+	return filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name()), true
+}
+
+// MainModules returns paths of the main modules which dir belongs to.
+// It finds go.mod in dir or its ancestors, then finds go.work in the same way unless GOWORK=off.
+// If go.work uses the module, it returns all modules used by go.work.
+// It returns nil if dir is not in a main module.
+func MainModules(dir string) ([]string, error) {
+	if isDependencyDir(dir) {
+		return nil, nil
+	}
+	modDir, ok, err := modutil.FindUp(dir, "go.mod")
+	if err != nil || !ok {
+		return nil, err
+	}
+	if isVendorDir(modDir, dir) {
+		return nil, nil
+	}
+	module, err := readModulePath(filepath.Join(modDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	if module == "std" || module == "cmd" {
+		// GOROOT
+		return nil, nil
+	}
+
+	workFile, err := findWorkFile(modDir)
+	if err != nil || workFile == "" {
+		return []string{module}, err
+	}
+	modules, uses, err := readWorkModules(workFile)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(uses, modDir) {
+		// go.work doesn't use the module.
+		return []string{module}, nil
+	}
+	return modules, nil
+}
+
+// isDependencyDir returns true if dir is in GOROOT or GOMODCACHE.
+func isDependencyDir(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{"GOROOT", "GOMODCACHE"} {
+		if root := goEnv(key); root != "" && isWithin(dir, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// isVendorDir returns true if dir is in the vendor directory of the module at modDir.
+func isVendorDir(modDir, dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	return isWithin(dir, filepath.Join(modDir, "vendor"))
+}
+
+// isWithin returns true if dir is root or its descendant.
+func isWithin(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// goEnv returns the go env variable key. The environment variable takes precedence like the go command,
+// then the default derived from go/build in the same way as the go command.
+// The go command is run only if the default directory doesn't exist (e.g. GOMODCACHE is set by go env -w).
+func goEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := buildDefault(key); value != "" {
+		if info, err := os.Stat(value); err == nil && info.IsDir() {
+			return value
+		}
+	}
+	return goEnvCommand()[key]
+}
+
+// buildDefault returns the default of GOROOT or GOMODCACHE.
+func buildDefault(key string) string {
+	switch key {
+	case "GOROOT":
+		return build.Default.GOROOT
+	case "GOMODCACHE":
+		if gopath := filepath.SplitList(build.Default.GOPATH); len(gopath) != 0 {
+			return filepath.Join(gopath[0], "pkg", "mod")
+		}
+	}
+	return ""
+}
+
+// goEnvCommand returns GOROOT and GOMODCACHE reported by the go command, or empty if it isn't available.
+var goEnvCommand = sync.OnceValue(func() map[string]string {
+	env := make(map[string]string)
+	out, err := exec.Command("go", "env", "-json", "GOROOT", "GOMODCACHE").Output()
+	if err != nil || json.Unmarshal(out, &env) != nil {
+		return map[string]string{}
+	}
+	return env
+})
+
+// findWorkFile returns go.work path in the same way as the go command.
+func findWorkFile(modDir string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return "", nil
+	case "":
+		workDir, ok, err := modutil.FindUp(modDir, "go.work")
+		if err != nil || !ok {
+			return "", err
+		}
+		return filepath.Join(workDir, "go.work"), nil
+	default:
+		return gowork, nil
+	}
+}
+
+func readModulePath(modFile string) (string, error) {
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return "", err
+	}
+	module := modfile.ModulePath(data)
+	if module == "" {
+		return "", fmt.Errorf("module path not found in %s", modFile)
+	}
+	return module, nil
+}
+
+// readWorkModules returns module paths and directories used by go.work.
+func readWorkModules(workFile string) ([]string, []string, error) {
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	work, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	workDir := filepath.Dir(workFile)
+	modules := make([]string, 0, len(work.Use))
+	dirs := make([]string, 0, len(work.Use))
+	for _, use := range work.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workDir, dir)
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		module, err := readModulePath(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return nil, nil, err
+		}
+		modules = append(modules, module)
+		dirs = append(dirs, dir)
+	}
+	return modules, dirs, nil
+}
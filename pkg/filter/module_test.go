@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/analysis"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMainModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	root := t.TempDir()
+	t.Setenv("GOMODCACHE", filepath.Join(root, "pkg", "mod"))
+	writeFiles(t, root, map[string]string{
+		"single/go.mod":                         "module example.com/single\n",
+		"single/a/b/b.go":                       "package b\n",
+		"single/vendor/example.com/v/v.go":      "package v\n",
+		"work/go.work":                          "go 1.24\n\nuse (\n\t./api\n\t./svc\n)\n",
+		"work/api/go.mod":                       "module example.com/api\n",
+		"work/svc/go.mod":                       "module example.com/svc\n",
+		"work/svc/handler/handler.go":           "package handler\n",
+		"work/other/go.mod":                     "module example.com/other\n",
+		"pkg/mod/example.com/dep@v1.0.0/go.mod": "module example.com/dep\n",
+		"goroot/src/go.mod":                     "module std\n",
+		"goroot/src/errors/errors.go":           "package errors\n",
+		"home@host/app/go.mod":                  "module example.com/app\n",
+		"home@host/app/vendor/go.mod":           "module example.com/app/vendor\n",
+	})
+
+	tests := []struct {
+		name string
+		dir  string
+		want []string
+	}{
+		{name: "module root", dir: "single", want: []string{"example.com/single"}},
+		{name: "nested package", dir: "single/a/b", want: []string{"example.com/single"}},
+		{name: "vendor", dir: "single/vendor/example.com/v", want: nil},
+		{name: "workspace", dir: "work/svc/handler", want: []string{"example.com/api", "example.com/svc"}},
+		{name: "module not used by workspace", dir: "work/other", want: []string{"example.com/other"}},
+		{name: "module cache", dir: "pkg/mod/example.com/dep@v1.0.0", want: nil},
+		{name: "GOROOT", dir: "goroot/src/errors", want: nil},
+		{name: "@ in path of main module", dir: "home@host/app", want: []string{"example.com/app"}},
+		{name: "module in a directory named vendor", dir: "home@host/app/vendor", want: []string{"example.com/app/vendor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MainModules(filepath.Join(root, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MainModules() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMainModules_GOWORKOff(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":    "go 1.24\n\nuse (\n\t./api\n\t./svc\n)\n",
+		"api/go.mod": "module example.com/api\n",
+		"svc/go.mod": "module example.com/svc\n",
+	})
+	got, err := MainModules(filepath.Join(root, "svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"example.com/svc"}, got); diff != "" {
+		t.Errorf("MainModules() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestForPass(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	log := slog.New(slog.DiscardHandler)
+	tests := []struct {
+		name       string
+		module     *analysis.Module
+		includes   string
+		wantTarget bool
+		wantSkip   bool
+	}{
+		{name: "main module", module: &analysis.Module{Path: "example.com/svc"}, wantTarget: true},
+		{name: "dependency", module: &analysis.Module{Path: "example.com/dep", Version: "v1.0.0"}, wantSkip: true},
+		{
+			name:       "dependency included explicitly",
+			module:     &analysis.Module{Path: "example.com/dep", Version: "v1.0.0"},
+			includes:   "./...",
+			wantTarget: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pass := &analysis.Pass{Module: tt.module}
+			packages, _, skipReason, err := ForPass(pass, log, tt.includes, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := skipReason != ""; got != tt.wantSkip {
+				t.Fatalf("skipReason = %q, want skip %v", skipReason, tt.wantSkip)
+			}
+			if tt.wantSkip {
+				return
+			}
+			if got := packages.IsTarget(tt.module.Path + "/handler"); got != tt.wantTarget {
+				t.Errorf("IsTarget() = %v, want %v", got, tt.wantTarget)
+			}
+		})
+	}
+}
@@ -4,41 +4,87 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"slices"
 )
 
 // https://www.logarithmic.net/pfh/blog/01208083168
 
+// Graph is a directed graph.
+// Vertices are numbered in insertion order, and adjacency is held by the numbers.
 type Graph[T comparable] struct {
-	Vertices []T
-	Edges    map[T][]T
+	vertices []T               // number to vertex.
+	index    map[T]int         // vertex to number.
+	adj      [][]int           // successors of each vertex in insertion order.
+	edges    map[edge]struct{} // to avoid duplicate edges.
+}
+
+type edge struct {
+	start, end int
 }
 
 // NewGraph returns new graph.
 func NewGraph[T comparable]() *Graph[T] {
 	return &Graph[T]{
-		Edges: make(map[T][]T),
+		index: make(map[T]int),
+		edges: make(map[edge]struct{}),
+	}
+}
+
+// AddVertex adds v if it is not added yet, and returns its number.
+func (g *Graph[T]) AddVertex(v T) int {
+	if i, ok := g.index[v]; ok {
+		return i
 	}
+	i := len(g.vertices)
+	g.vertices = append(g.vertices, v)
+	g.index[v] = i
+	g.adj = append(g.adj, nil)
+	return i
 }
 
+// AddEdge adds edge from start to end. Duplicate edges are ignored.
 func (g *Graph[T]) AddEdge(start, end T) {
-	if idx := slices.Index(g.Vertices, start); idx == -1 {
-		g.Vertices = append(g.Vertices, start)
+	s := g.AddVertex(start)
+	e := g.AddVertex(end)
+	key := edge{start: s, end: e}
+	if _, ok := g.edges[key]; ok {
+		return
 	}
-	if idx := slices.Index(g.Vertices, end); idx == -1 {
-		g.Vertices = append(g.Vertices, end)
+	g.edges[key] = struct{}{}
+	g.adj[s] = append(g.adj[s], e)
+}
+
+// Vertices returns vertices in insertion order.
+func (g *Graph[T]) Vertices() []T {
+	return g.vertices
+}
+
+// NumEdges returns the number of edges.
+func (g *Graph[T]) NumEdges() int {
+	return len(g.edges)
+}
+
+// Successors returns ends of edges from v in insertion order.
+func (g *Graph[T]) Successors(v T) []T {
+	i, ok := g.index[v]
+	if !ok {
+		return nil
 	}
-	if idx := slices.Index(g.Edges[start], end); idx == -1 {
-		g.Edges[start] = append(g.Edges[start], end)
+	ret := make([]T, len(g.adj[i]))
+	for j, w := range g.adj[i] {
+		ret[j] = g.vertices[w]
 	}
+	return ret
 }
 
 func (g *Graph[T]) LogValue() slog.Value {
-	coreData := make(map[string][]string)
-	for v, wlist := range g.Edges {
-		coreData2 := make([]string, 0, len(wlist))
-		for _, w := range wlist {
-			coreData2 = append(coreData2, fmt.Sprintf("%v", w))
+	coreData := make(map[string][]string, len(g.vertices))
+	for i, v := range g.vertices {
+		if len(g.adj[i]) == 0 {
+			continue
+		}
+		coreData2 := make([]string, 0, len(g.adj[i]))
+		for _, w := range g.adj[i] {
+			coreData2 = append(coreData2, fmt.Sprintf("%v", g.vertices[w]))
 		}
 		coreData[fmt.Sprintf("%v", v)] = coreData2
 	}
@@ -49,51 +95,99 @@ func (g *Graph[T]) LogValue() slog.Value {
 	return slog.StringValue(string(jsonBytes))
 }
 
+// Decomposition returns strongly connected components of g in reverse topological order,
+// i.e. a component comes after all components reachable from it.
+// It is an iterative Tarjan's algorithm, so deep graphs don't exhaust the stack.
+//
+//nolint:gocognit // Tarjan's algorithm with explicit stack.
 func Decomposition[T comparable](g *Graph[T]) [][]T {
-	index := make(map[T]int)
-	lowLink := make(map[T]int)
-	stack := make([]T, 0)
-	onStack := make(map[T]bool)
+	n := len(g.vertices)
+	const unvisited = -1
+	index := make([]int, n)
+	for i := range index {
+		index[i] = unvisited
+	}
+	lowLink := make([]int, n)
+	onStack := make([]bool, n)
+	stack := make([]int, 0)
 	var currentIndex int
 
-	var result [][]T
-	var strongConnect func(v T)
-	strongConnect = func(v T) {
+	// frame is a vertex under visit and the position of the next successor to visit.
+	type frame struct {
+		v    int
+		next int
+	}
+	var frames []frame
+	visit := func(v int) {
 		index[v] = currentIndex
 		lowLink[v] = currentIndex
 		currentIndex++
 		stack = append(stack, v)
 		onStack[v] = true
+		frames = append(frames, frame{v: v})
+	}
 
-		for _, w := range g.Edges[v] {
-			if _, ok := index[w]; !ok {
-				strongConnect(w)
-				lowLink[v] = min(lowLink[v], lowLink[w])
-			} else if onStack[w] {
-				lowLink[v] = min(lowLink[v], lowLink[w])
-			}
+	var result [][]T
+	for root := range n {
+		if index[root] != unvisited {
+			continue
 		}
+		visit(root)
+		for len(frames) > 0 {
+			top := &frames[len(frames)-1]
+			v := top.v
+			if top.next < len(g.adj[v]) {
+				w := g.adj[v][top.next]
+				top.next++
+				if index[w] == unvisited {
+					visit(w)
+				} else if onStack[w] {
+					lowLink[v] = min(lowLink[v], lowLink[w])
+				}
+				continue
+			}
 
-		if lowLink[v] == index[v] {
-			var w T
-			var scc []T
-			for {
-				w, stack = stack[len(stack)-1], stack[:len(stack)-1]
-				onStack[w] = false
-				scc = append(scc, w)
-				if w == v {
-					break
+			// all successors of v are visited.
+			frames = frames[:len(frames)-1]
+			if lowLink[v] == index[v] {
+				var scc []T
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					scc = append(scc, g.vertices[w])
+					if w == v {
+						break
+					}
 				}
+				result = append(result, scc)
+			}
+			if len(frames) > 0 {
+				parent := frames[len(frames)-1].v
+				lowLink[parent] = min(lowLink[parent], lowLink[v])
 			}
-			result = append(result, scc)
 		}
 	}
 
-	for _, v := range g.Vertices {
-		if _, ok := index[v]; !ok {
-			strongConnect(v)
+	return result
+}
+
+// SCCs is strongly connected components returned by Decomposition.
+// It is logged as JSON array of components.
+type SCCs[T comparable] [][]T
+
+func (sccs SCCs[T]) LogValue() slog.Value {
+	coreData := make([][]string, len(sccs))
+	for i, scc := range sccs {
+		coreData2 := make([]string, len(scc))
+		for j, v := range scc {
+			coreData2[j] = fmt.Sprintf("%v", v)
 		}
+		coreData[i] = coreData2
 	}
-
-	return result
+	jsonBytes, err := json.Marshal(coreData)
+	if err != nil {
+		return slog.Value{}
+	}
+	return slog.StringValue(string(jsonBytes))
 }
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"math/rand/v2"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -118,3 +119,93 @@ func TestDecomposition(t *testing.T) {
 		})
 	}
 }
+
+func TestAddEdge(t *testing.T) {
+	t.Parallel()
+	g := NewGraph[string]()
+	g.AddEdge("a", "b")
+	g.AddEdge("a", "c")
+	g.AddEdge("a", "b")
+	g.AddEdge("c", "a")
+	if diff := cmp.Diff([]string{"a", "b", "c"}, g.Vertices()); diff != "" {
+		t.Errorf("Vertices() diff (-want,+got) %s", diff)
+	}
+	if diff := cmp.Diff([]string{"b", "c"}, g.Successors("a")); diff != "" {
+		t.Errorf("Successors() diff (-want,+got) %s", diff)
+	}
+	if got := g.Successors("x"); got != nil {
+		t.Errorf("Successors() of unknown vertex = %v, want nil", got)
+	}
+}
+
+func TestDecomposition_DeepChain(t *testing.T) {
+	t.Parallel()
+	const n = 1_000_000
+	g := chainGraph(n)
+	g.AddEdge(n-1, 0)
+	got := Decomposition(g)
+	if len(got) != 1 || len(got[0]) != n {
+		t.Errorf("Decomposition() returns %d SCCs, want 1 SCC with %d vertices", len(got), n)
+	}
+}
+
+const benchSize = 100_000
+
+// chainGraph returns 0 -> 1 -> ... -> n-1.
+func chainGraph(n int) *Graph[int] {
+	g := NewGraph[int]()
+	for i := range n - 1 {
+		g.AddEdge(i, i+1)
+	}
+	return g
+}
+
+// randomEdges returns edges of random graph with n vertices and degree edges per vertex.
+func randomEdges(n, degree int) [][2]int {
+	r := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // deterministic graph for benchmark.
+	edges := make([][2]int, 0, n*degree)
+	for i := range n {
+		for range degree {
+			edges = append(edges, [2]int{i, r.IntN(n)})
+		}
+	}
+	return edges
+}
+
+func BenchmarkAddEdge(b *testing.B) {
+	edges := randomEdges(benchSize, 4)
+	b.ResetTimer()
+	for range b.N {
+		g := NewGraph[int]()
+		for _, e := range edges {
+			g.AddEdge(e[0], e[1])
+		}
+	}
+}
+
+func BenchmarkDecomposition_Chain(b *testing.B) {
+	g := chainGraph(benchSize)
+	b.ResetTimer()
+	for range b.N {
+		Decomposition(g)
+	}
+}
+
+func BenchmarkDecomposition_Random(b *testing.B) {
+	g := NewGraph[int]()
+	for _, e := range randomEdges(benchSize, 4) {
+		g.AddEdge(e[0], e[1])
+	}
+	b.ResetTimer()
+	for range b.N {
+		Decomposition(g)
+	}
+}
+
+func TestSCCs_LogValue(t *testing.T) {
+	t.Parallel()
+	got := SCCs[int]{{1}, {2, 3}}.LogValue().String()
+	if diff := cmp.Diff(`[["1"],["2","3"]]`, got); diff != "" {
+		t.Errorf("LogValue() mismatch (-want +got):\n%s", diff)
+	}
+}
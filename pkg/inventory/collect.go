@@ -0,0 +1,209 @@
+package inventory
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis/checker"
+
+	"github.com/cloverrose/rpcguard/pkg/report"
+)
+
+// SchemaVersion is version of the JSON inventory schema.
+// It is incremented when a field is removed or changes its meaning.
+const SchemaVersion = "1"
+
+// Inventory lists RPC methods of all packages with verdicts of each analyzer.
+type Inventory struct {
+	Version  string    `json:"version"`
+	Rules    []string  `json:"rules"` // rule ids of analyzers which judge RPC methods. e.g. wraperr
+	Summary  Summary   `json:"summary"`
+	Packages []Package `json:"packages"`
+}
+
+// Summary counts RPC methods.
+type Summary struct {
+	Methods int `json:"methods"`
+	// Analyzed is the number of RPC methods which are not skipped by any analyzer.
+	Analyzed int `json:"analyzed"`
+	// Coverage is Analyzed / Methods in percent. It is 100 if there are no RPC methods.
+	Coverage float64 `json:"coverage"`
+	// Verdicts counts verdicts of each rule.
+	Verdicts map[string]map[Verdict]int `json:"verdicts"`
+}
+
+// Package lists RPC methods of a package.
+type Package struct {
+	Path    string  `json:"path"`
+	Methods []Entry `json:"methods"`
+}
+
+// Entry is an RPC method with verdicts of each analyzer.
+type Entry struct {
+	Service   string               `json:"service"`
+	Procedure string               `json:"procedure"`
+	File      string               `json:"file"` // relative to the base directory if possible, slash separated.
+	Line      int                  `json:"line"`
+	Verdicts  map[string]Judgement `json:"verdicts"` // keyed by rule id.
+}
+
+// Judgement is a verdict of an analyzer.
+type Judgement struct {
+	Verdict Verdict `json:"verdict"`
+	Reason  string  `json:"reason,omitempty"`
+}
+
+// Analyzed returns true if no analyzer skipped the method.
+func (e *Entry) Analyzed() bool {
+	for _, j := range e.Verdicts {
+		if j.Verdict == VerdictSkipped {
+			return false
+		}
+	}
+	return len(e.Verdicts) != 0
+}
+
+// Collect builds inventory from results of actions (e.g. checker.Graph.Roots).
+// Actions whose result is not *Result are ignored.
+// File paths are made relative to baseDir if they are inside it.
+func Collect(actions []*checker.Action, baseDir string) *Inventory {
+	type key struct{ pkg, service, name string }
+	entries := make(map[key]*Entry)
+	packages := make(map[string][]*Entry)
+	rules := make(map[string]bool)
+	for _, act := range actions {
+		result, ok := act.Result.(*Result)
+		if !ok || result == nil {
+			continue
+		}
+		rule := report.RuleID(act.Analyzer)
+		rules[rule] = true
+		for _, m := range result.Methods {
+			k := key{pkg: act.Package.PkgPath, service: m.Service, name: m.Name}
+			entry, ok := entries[k]
+			if !ok {
+				// ignore //line directives, the same with report.
+				position := act.Package.Fset.PositionFor(m.Pos, false)
+				entry = &Entry{
+					Service:   m.Service,
+					Procedure: m.Name,
+					File:      report.RelPath(baseDir, position.Filename),
+					Line:      position.Line,
+					Verdicts:  make(map[string]Judgement),
+				}
+				entries[k] = entry
+				packages[k.pkg] = append(packages[k.pkg], entry)
+			}
+			entry.Verdicts[rule] = Judgement{Verdict: m.Verdict, Reason: m.Reason}
+		}
+	}
+
+	inv := &Inventory{
+		Version:  SchemaVersion,
+		Rules:    slices.Sorted(maps.Keys(rules)),
+		Packages: []Package{},
+		Summary:  Summary{Verdicts: make(map[string]map[Verdict]int)},
+	}
+	for _, path := range slices.Sorted(maps.Keys(packages)) {
+		pkg := Package{Path: path}
+		for _, entry := range packages[path] {
+			pkg.Methods = append(pkg.Methods, *entry)
+		}
+		slices.SortFunc(pkg.Methods, func(a, b Entry) int {
+			return cmp.Or(
+				cmp.Compare(a.File, b.File),
+				cmp.Compare(a.Line, b.Line),
+				cmp.Compare(a.Service, b.Service),
+				cmp.Compare(a.Procedure, b.Procedure),
+			)
+		})
+		inv.Packages = append(inv.Packages, pkg)
+	}
+	for _, pkg := range inv.Packages {
+		for _, entry := range pkg.Methods {
+			inv.Summary.Methods++
+			if entry.Analyzed() {
+				inv.Summary.Analyzed++
+			}
+			for rule, j := range entry.Verdicts {
+				if inv.Summary.Verdicts[rule] == nil {
+					inv.Summary.Verdicts[rule] = make(map[Verdict]int)
+				}
+				inv.Summary.Verdicts[rule][j.Verdict]++
+			}
+		}
+	}
+	inv.Summary.Coverage = 100
+	if inv.Summary.Methods != 0 {
+		inv.Summary.Coverage = float64(inv.Summary.Analyzed) * 100 / float64(inv.Summary.Methods)
+	}
+	return inv
+}
+
+// WriteJSON writes inv as JSON.
+func WriteJSON(w io.Writer, inv *Inventory) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+// WriteMarkdown writes inv as Markdown tables, one table per package.
+func WriteMarkdown(w io.Writer, inv *Inventory) error {
+	var b strings.Builder
+	b.WriteString("# RPC method inventory\n\n")
+	fmt.Fprintf(&b, "%d of %d RPC methods analyzed (%.1f%%).\n", inv.Summary.Analyzed, inv.Summary.Methods, inv.Summary.Coverage)
+	if len(inv.Rules) != 0 {
+		verdicts := []Verdict{VerdictOK, VerdictBad, VerdictUnknown, VerdictSkipped}
+		b.WriteString("\n| Rule |")
+		for _, v := range verdicts {
+			fmt.Fprintf(&b, " %s |", v)
+		}
+		b.WriteString("\n|---|" + strings.Repeat("---:|", len(verdicts)) + "\n")
+		for _, rule := range inv.Rules {
+			fmt.Fprintf(&b, "| %s |", rule)
+			for _, v := range verdicts {
+				fmt.Fprintf(&b, " %d |", inv.Summary.Verdicts[rule][v])
+			}
+			b.WriteString("\n")
+		}
+	}
+	for _, pkg := range inv.Packages {
+		fmt.Fprintf(&b, "\n## %s\n\n", pkg.Path)
+		b.WriteString("| Method | Location |")
+		for _, rule := range inv.Rules {
+			fmt.Fprintf(&b, " %s |", rule)
+		}
+		b.WriteString("\n|---|---|" + strings.Repeat("---|", len(inv.Rules)) + "\n")
+		for _, entry := range pkg.Methods {
+			fmt.Fprintf(&b, "| %s | %s:%d |", markdownEscape(shortService(entry.Service)+"."+entry.Procedure), entry.File, entry.Line)
+			for _, rule := range inv.Rules {
+				j, ok := entry.Verdicts[rule]
+				switch {
+				case !ok:
+					b.WriteString(" - |")
+				case j.Reason == "":
+					fmt.Fprintf(&b, " %s |", j.Verdict)
+				default:
+					fmt.Fprintf(&b, " %s: %s |", j.Verdict, markdownEscape(j.Reason))
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shortService returns type name without package path. e.g. example.com/greet.Server -> Server
+func shortService(service string) string {
+	return service[strings.LastIndex(service, ".")+1:]
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,79 @@
+// Package inventory lists RPC methods found by analyzers with their verdicts,
+// so that users can see which RPC methods were actually analyzed.
+package inventory
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+// Verdict is the result of an analyzer for an RPC method.
+type Verdict string
+
+const (
+	VerdictOK      Verdict = "ok"
+	VerdictBad     Verdict = "bad"
+	VerdictUnknown Verdict = "unknown"
+	VerdictSkipped Verdict = "skipped"
+)
+
+// Method is an RPC method judged by an analyzer.
+type Method struct {
+	Service string // receiver type. e.g. example.com/greet.Server
+	Name    string
+	Pos     token.Pos
+	Verdict Verdict
+	Reason  string // why the method is skipped, bad or unknown. It may be empty.
+}
+
+// Result is the result of analyzers which judge RPC methods (Analyzer.ResultType).
+type Result struct {
+	Methods []*Method
+}
+
+// Add adds verdict of RPC method fn.
+func (r *Result) Add(fn *ssa.Function, verdict Verdict, reason string) {
+	r.Methods = append(r.Methods, &Method{
+		Service: service(fn),
+		Name:    fn.Name(),
+		Pos:     fn.Pos(),
+		Verdict: verdict,
+		Reason:  reason,
+	})
+}
+
+// SkipPackage adds all RPC methods of the package as skipped.
+// RPC methods are found by signature, since rpcmethod.Checker may not be built for the package.
+func (r *Result) SkipPackage(pass *analysis.Pass, reason string) {
+	ssaData, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	if !ok {
+		return
+	}
+	for _, fn := range ssaData.SrcFuncs {
+		if rpcmethod.LooksLikeRPCMethod(fn) {
+			r.Add(fn, VerdictSkipped, reason)
+		}
+	}
+}
+
+func service(fn *ssa.Function) string {
+	recv := fn.Signature.Recv()
+	if recv == nil {
+		return ""
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return typ.String()
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
@@ -0,0 +1,133 @@
+package inventory_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+	"github.com/cloverrose/rpcguard/pkg/inventory"
+
+	"github.com/cloverrose/rpcguard/passes/callvalidate"
+	"github.com/cloverrose/rpcguard/passes/wraperr"
+)
+
+// collect runs analyzers with checker instead of analysistest,
+// since analysistest requires want comments of each analyzer in the same files.
+func collect(t *testing.T) *inventory.Inventory {
+	t.Helper()
+	dir := filepath.Join(analysistest.TestData(), "src", "a")
+	configtest.Set(t, &wraperr.LogConfig.Level, "ERROR")
+	configtest.Set(t, &wraperr.ExcludeFiles, "excluded")
+	configtest.Set(t, &callvalidate.LogConfig.Level, "ERROR")
+	configtest.Set(t, &callvalidate.ExcludeFiles, "excluded")
+	configtest.Set(t, &callvalidate.ValidateMethods, "a:validate")
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("failed to load packages")
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{wraperr.Analyzer, callvalidate.Analyzer}, pkgs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			t.Fatal(act.Err)
+		}
+	}
+	return inventory.Collect(graph.Roots, dir)
+}
+
+func TestCollect(t *testing.T) {
+	got := collect(t)
+	skipped := func(reason string) map[string]inventory.Judgement {
+		return map[string]inventory.Judgement{
+			"callvalidate": {Verdict: inventory.VerdictSkipped, Reason: reason},
+			"wraperr":      {Verdict: inventory.VerdictSkipped, Reason: reason},
+		}
+	}
+	want := &inventory.Inventory{
+		Version: inventory.SchemaVersion,
+		Rules:   []string{"callvalidate", "wraperr"},
+		Summary: inventory.Summary{
+			Methods:  4,
+			Analyzed: 2,
+			Coverage: 50,
+			Verdicts: map[string]map[inventory.Verdict]int{
+				"callvalidate": {inventory.VerdictOK: 1, inventory.VerdictBad: 1, inventory.VerdictSkipped: 2},
+				"wraperr":      {inventory.VerdictOK: 1, inventory.VerdictBad: 1, inventory.VerdictSkipped: 2},
+			},
+		},
+		Packages: []inventory.Package{{
+			Path: "a",
+			Methods: []inventory.Entry{
+				{
+					Service: "a.App", Procedure: "OK", File: "a.go", Line: 18,
+					Verdicts: map[string]inventory.Judgement{
+						"callvalidate": {Verdict: inventory.VerdictOK},
+						"wraperr":      {Verdict: inventory.VerdictOK},
+					},
+				},
+				{
+					Service: "a.App", Procedure: "Bad", File: "a.go", Line: 25,
+					Verdicts: map[string]inventory.Judgement{
+						"callvalidate": {Verdict: inventory.VerdictBad},
+						"wraperr":      {Verdict: inventory.VerdictBad},
+					},
+				},
+				{
+					Service: "a.App", Procedure: "Excluded", File: "excluded.go", Line: 9,
+					Verdicts: skipped(`file is excluded by "excluded"`),
+				},
+				{
+					Service: "a.App", Procedure: "Generated", File: "generated.go", Line: 11,
+					Verdicts: skipped("generated file"),
+				},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Collect() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := inventory.WriteMarkdown(&buf, collect(t)); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"# RPC method inventory",
+		"",
+		"2 of 4 RPC methods analyzed (50.0%).",
+		"",
+		"| Rule | ok | bad | unknown | skipped |",
+		"|---|---:|---:|---:|---:|",
+		"| callvalidate | 1 | 1 | 0 | 2 |",
+		"| wraperr | 1 | 1 | 0 | 2 |",
+		"",
+		"## a",
+		"",
+		"| Method | Location | callvalidate | wraperr |",
+		"|---|---|---|---|",
+		"| App.OK | a.go:18 | ok | ok |",
+		"| App.Bad | a.go:25 | bad | bad |",
+		`| App.Excluded | excluded.go:9 | skipped: file is excluded by "excluded" | skipped: file is excluded by "excluded" |`,
+		"| App.Generated | generated.go:11 | skipped: generated file | skipped: generated file |",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
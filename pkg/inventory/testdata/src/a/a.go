@@ -0,0 +1,27 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct{}
+
+func validate(_ *Message) error {
+	return nil
+}
+
+func (app *App) OK(_ context.Context, req *connect.Request[Message]) (*connect.Response[Message], error) {
+	if err := validate(req.Msg); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return connect.NewResponse(&Message{}), nil
+}
+
+func (app *App) Bad(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	return nil, errors.New("bad")
+}
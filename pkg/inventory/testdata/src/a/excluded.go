@@ -0,0 +1,11 @@
+package a
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+)
+
+func (app *App) Excluded(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) {
+	return connect.NewResponse(&Message{}), nil
+}
@@ -5,26 +5,38 @@ import (
 	"fmt"
 	"go/token"
 	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/ssa"
+
+	"github.com/cloverrose/rpcguard/pkg/modutil"
 )
 
 const (
-	valueKey = "value"
-	posKey   = "pos"
+	valueKey    = "value"
+	posKey      = "pos"
+	analyzerKey = "analyzer"
+	packageKey  = "package"
 )
 
 // Config is logging configuration.
+// Analyzers log to stderr with WARN level by default, not to pollute go vet output.
 type Config struct {
-	Level  string // "debug", "info", "warn", "error"
-	File   string // file name to write log
+	Level  string // "debug", "info", "warn", "error". Default is "warn".
+	File   string // file name to write log. All packages are appended to the file.
+	Dir    string // directory to write log, one file per analyzer and package. It takes precedence over File.
 	Format string // "json" or "text"
 }
 
-func SetDefault(cfg Config, pass *analysis.Pass) (closer func() error, err error) {
+// New returns logger for pass. Records have the analyzer name and the package path,
+// and positions of ssa values are written relative to the module root.
+// It writes to stderr unless cfg.File or cfg.Dir is set, so that go vet output isn't polluted.
+// The caller must call closer after the pass.
+func New(cfg Config, pass *analysis.Pass) (log *slog.Logger, closer func() error, err error) {
 	opts := &slog.HandlerOptions{
 		Level: convertLogLevel(cfg.Level),
 	}
@@ -33,11 +45,16 @@ func SetDefault(cfg Config, pass *analysis.Pass) (closer func() error, err error
 	closer = func() error { return nil }
 
 	// configure writer
-	writer := os.Stdout
-	if cfg.File != "" {
-		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	writer := os.Stderr
+	if name := fileName(cfg, pass); name != "" {
+		if cfg.Dir != "" {
+			if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+				return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+			}
+		}
+		file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open log file: %w", err)
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
 		}
 		writer = file
 		closer = file.Close
@@ -52,21 +69,45 @@ func SetDefault(cfg Config, pass *analysis.Pass) (closer func() error, err error
 		handler = slog.NewJSONHandler(writer, opts)
 	}
 
-	// set default logger
-	slog.SetDefault(slog.New(&ValueHandler{
+	log = slog.New(&ValueHandler{
 		handler: handler,
-		pass:    pass,
-	}))
-	return closer, nil
+		fset:    pass.Fset,
+		root:    moduleRoot(pass),
+	}).With(slog.String(analyzerKey, pass.Analyzer.Name), slog.String(packageKey, pass.Pkg.Path()))
+	return log, closer, nil
+}
+
+// fileName returns log file of the pass. It returns empty string to write to stderr.
+func fileName(cfg Config, pass *analysis.Pass) string {
+	if cfg.Dir == "" {
+		return cfg.File
+	}
+	// package path can't be a file name as is. e.g. rpc_wraperr.github.com%2Ffoo%2Fbar.log
+	return filepath.Join(cfg.Dir, pass.Analyzer.Name+"."+url.PathEscape(pass.Pkg.Path())+".log")
+}
+
+// moduleRoot returns the directory of go.mod of the package. It returns empty string if not found.
+func moduleRoot(pass *analysis.Pass) string {
+	if len(pass.Files) == 0 {
+		return ""
+	}
+	dir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+	root, ok, err := modutil.ModuleRoot(dir)
+	if err != nil || !ok {
+		return ""
+	}
+	return root
 }
 
 func Attr(value ssa.Value) slog.Attr {
 	return slog.Group(valueKey, slog.Any(posKey, value.Pos()), slog.String("name", value.Name()), slog.String("str", value.String()))
 }
 
+// ValueHandler converts token.Pos of ssa values (see Attr) into file:line:column.
 type ValueHandler struct {
 	handler slog.Handler
-	pass    *analysis.Pass
+	fset    *token.FileSet
+	root    string // module root. File names are written relative to it.
 }
 
 func (h *ValueHandler) Enabled(ctx context.Context, level slog.Level) bool {
@@ -74,11 +115,11 @@ func (h *ValueHandler) Enabled(ctx context.Context, level slog.Level) bool {
 }
 
 func (h *ValueHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h.handler.WithAttrs(attrs)
+	return &ValueHandler{handler: h.handler.WithAttrs(attrs), fset: h.fset, root: h.root}
 }
 
 func (h *ValueHandler) WithGroup(name string) slog.Handler {
-	return h.handler.WithGroup(name)
+	return &ValueHandler{handler: h.handler.WithGroup(name), fset: h.fset, root: h.root}
 }
 
 func (h *ValueHandler) Handle(ctx context.Context, r slog.Record) error {
@@ -96,8 +137,7 @@ func (h *ValueHandler) Handle(ctx context.Context, r slog.Record) error {
 				newGroups = append(newGroups, subAttr)
 				continue
 			}
-			posStr := getPosStr(h.pass, p)
-			newGroups = append(newGroups, slog.String(posKey, posStr))
+			newGroups = append(newGroups, slog.String(posKey, h.posString(p)))
 		}
 		newRecord.AddAttrs(slog.Group(valueKey, newGroups...))
 		return true
@@ -117,17 +157,21 @@ func getPos(attr slog.Attr) (token.Pos, bool) {
 	return value, true
 }
 
-func getPosStr(pass *analysis.Pass, pos token.Pos) string {
-	position := pass.Fset.Position(pos)
-	return fmt.Sprintf("%s:%d:%d", getFileName(position.Filename), position.Line, position.Column)
+func (h *ValueHandler) posString(pos token.Pos) string {
+	position := h.fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d", h.relPath(position.Filename), position.Line, position.Column)
 }
 
-func getFileName(path string) string {
-	if !strings.HasPrefix(path, "/var/folders") {
+// relPath returns path relative to the module root if path is inside it.
+func (h *ValueHandler) relPath(path string) string {
+	if h.root == "" || !filepath.IsAbs(path) {
 		return path
 	}
-	elems := strings.Split(path, "/")
-	return elems[len(elems)-1]
+	rel, err := filepath.Rel(h.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
 }
 
 func convertLogLevel(level string) slog.Level {
@@ -141,6 +185,6 @@ func convertLogLevel(level string) slog.Level {
 	case "error":
 		return slog.LevelError
 	default:
-		return slog.LevelInfo
+		return slog.LevelWarn
 	}
 }
@@ -0,0 +1,106 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/cloverrose/rpcguard/pkg/logger"
+)
+
+func newPass(t *testing.T) *analysis.Pass {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(root, "handler", "handler.go")
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte("package handler\n\nfunc F() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &analysis.Pass{
+		Analyzer: &analysis.Analyzer{Name: "rpc_test"},
+		Fset:     fset,
+		Files:    []*ast.File{file},
+		Pkg:      types.NewPackage("example.com/app/handler", "handler"),
+	}
+}
+
+func TestNew_Dir(t *testing.T) {
+	pass := newPass(t)
+	dir := t.TempDir()
+	log, closer, err := logger.New(logger.Config{Level: "debug", Dir: dir, Format: "json"}, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same shape as logger.Attr, which requires ssa.Value.
+	log.Debug("check", slog.Group("value", slog.Any("pos", pass.Files[0].Decls[0].Pos())))
+	log.Info("found")
+	if err := closer(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "rpc_test.example.com%2Fapp%2Fhandler.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first record of the two.
+	var got map[string]any
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	delete(got, "time")
+	want := map[string]any{
+		"level":    "DEBUG",
+		"msg":      "check",
+		"analyzer": "rpc_test",
+		"package":  "example.com/app/handler",
+		"value":    map[string]any{"pos": "handler/handler.go:3:1"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("log record mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNew_DefaultLevel(t *testing.T) {
+	pass := newPass(t)
+	file := filepath.Join(t.TempDir(), "log.txt")
+	log, closer, err := logger.New(logger.Config{File: file}, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("found RPC method")
+	log.Warn("could not analyze")
+	if err := closer(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("want only one WARN record: %v\n%s", err, data)
+	}
+	if got["msg"] != "could not analyze" {
+		t.Errorf("msg = %v, want could not analyze", got["msg"])
+	}
+}
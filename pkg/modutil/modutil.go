@@ -0,0 +1,36 @@
+// Package modutil finds go.mod and go.work files of directories.
+package modutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ModuleRoot returns directory of the nearest go.mod of dir.
+func ModuleRoot(dir string) (string, bool, error) {
+	return FindUp(dir, "go.mod")
+}
+
+// FindUp returns dir or its nearest ancestor which has file named name.
+func FindUp(dir, name string) (string, bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false, err
+	}
+	for {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if err == nil {
+			return dir, true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", false, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,27 @@
+package modutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloverrose/rpcguard/pkg/modutil"
+)
+
+func TestModuleRoot(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := modutil.ModuleRoot(filepath.Join(root, "a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || got != root {
+		t.Errorf("ModuleRoot() = %q, %v, want %q, true", got, ok, root)
+	}
+}
@@ -0,0 +1,235 @@
+// Package report converts diagnostics of rpcguard analyzers into JSON and SARIF reports.
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/cloverrose/rpcguard/pkg/rpcmethod"
+)
+
+// SchemaVersion is version of the JSON report schema.
+// It is incremented when a field is removed or changes its meaning.
+const SchemaVersion = "1"
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// warningRules are rules reported with warning severity, as well as functions that cannot be analyzed. Other rules are error.
+var warningRules = map[string]bool{
+	"wraperr/unknown": true,
+}
+
+// Report is the JSON report.
+type Report struct {
+	Version     string       `json:"version"`
+	Rules       []Rule       `json:"rules"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Rule describes an analyzer.
+type Rule struct {
+	ID          string `json:"id"`       // e.g. wraperr
+	Analyzer    string `json:"analyzer"` // e.g. rpc_wraperr
+	Description string `json:"description"`
+}
+
+// Diagnostic is a reported problem.
+type Diagnostic struct {
+	RuleID         string         `json:"ruleId"` // rule id, with category if any. e.g. wraperr, wraperr/unknown
+	Analyzer       string         `json:"analyzer"`
+	Severity       string         `json:"severity"`
+	Message        string         `json:"message"`
+	Service        string         `json:"service,omitempty"`   // receiver type of RPC method. e.g. example.com/greet.Server
+	Procedure      string         `json:"procedure,omitempty"` // RPC method name. e.g. Greet
+	Location       Location       `json:"location"`
+	Related        []Related      `json:"related,omitempty"`
+	SuggestedFixes []SuggestedFix `json:"suggestedFixes,omitempty"`
+}
+
+// Location is a range in a file. Line and column are 1-based, and end is exclusive.
+type Location struct {
+	File      string `json:"file"` // relative to the base directory if possible, slash separated.
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+}
+
+// Related is a location related to a diagnostic. E.g. the function an error comes from.
+type Related struct {
+	Message  string   `json:"message"`
+	Location Location `json:"location"`
+}
+
+// SuggestedFix is a set of edits that fixes a diagnostic.
+type SuggestedFix struct {
+	Message string `json:"message"`
+	Edits   []Edit `json:"edits"`
+}
+
+// Edit replaces text in location with NewText.
+type Edit struct {
+	Location Location `json:"location"`
+	NewText  string   `json:"newText"`
+}
+
+// WriteText writes r in the same format as go vet. e.g. a.go:1:2: message (wraperr)
+func WriteText(w io.Writer, r *Report) error {
+	for _, d := range r.Diagnostics {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s (%s)\n", d.Location.File, d.Location.Line, d.Location.Column, d.Message, d.RuleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RuleID returns rule id of analyzer. e.g. rpc_wraperr -> wraperr
+func RuleID(analyzer *analysis.Analyzer) string {
+	return strings.TrimPrefix(analyzer.Name, "rpc_")
+}
+
+// Collect builds report from diagnostics of actions (e.g. checker.Graph.Roots).
+// File paths are made relative to baseDir if they are inside it.
+func Collect(analyzers []*analysis.Analyzer, actions []*checker.Action, baseDir string) *Report {
+	r := &Report{
+		Version:     SchemaVersion,
+		Rules:       make([]Rule, len(analyzers)),
+		Diagnostics: []Diagnostic{},
+	}
+	for i, a := range analyzers {
+		r.Rules[i] = Rule{ID: RuleID(a), Analyzer: a.Name, Description: a.Doc}
+	}
+	for _, act := range actions {
+		c := &converter{fset: act.Package.Fset, files: act.Package.Syntax, info: act.Package.TypesInfo, baseDir: baseDir}
+		for _, d := range act.Diagnostics {
+			r.Diagnostics = append(r.Diagnostics, c.convert(act.Analyzer, d))
+		}
+	}
+	slices.SortFunc(r.Diagnostics, func(a, b Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Location.File, b.Location.File),
+			cmp.Compare(a.Location.Line, b.Location.Line),
+			cmp.Compare(a.Location.Column, b.Location.Column),
+			cmp.Compare(a.RuleID, b.RuleID),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+	return r
+}
+
+type converter struct {
+	fset    *token.FileSet
+	files   []*ast.File
+	info    *types.Info
+	baseDir string
+}
+
+func (c *converter) convert(a *analysis.Analyzer, d analysis.Diagnostic) Diagnostic {
+	ruleID := RuleID(a)
+	if d.Category != "" {
+		ruleID += "/" + d.Category
+	}
+	severity := SeverityError
+	if warningRules[ruleID] || d.Category == rpcmethod.CategoryNotAnalyzed {
+		severity = SeverityWarning
+	}
+	ret := Diagnostic{
+		RuleID:   ruleID,
+		Analyzer: a.Name,
+		Severity: severity,
+		Message:  d.Message,
+		Location: c.location(d.Pos, d.End),
+	}
+	ret.Service, ret.Procedure = c.rpcMethod(d.Pos)
+	for _, related := range d.Related {
+		ret.Related = append(ret.Related, Related{
+			Message:  related.Message,
+			Location: c.location(related.Pos, related.End),
+		})
+	}
+	for _, fix := range d.SuggestedFixes {
+		edits := make([]Edit, len(fix.TextEdits))
+		for i, edit := range fix.TextEdits {
+			edits[i] = Edit{Location: c.location(edit.Pos, edit.End), NewText: string(edit.NewText)}
+		}
+		ret.SuggestedFixes = append(ret.SuggestedFixes, SuggestedFix{Message: fix.Message, Edits: edits})
+	}
+	return ret
+}
+
+// location returns location of the range in the file, ignoring //line directives.
+// Reports point to the files actually analyzed, so that fixes can be applied to them.
+func (c *converter) location(pos, end token.Pos) Location {
+	start := c.fset.PositionFor(pos, false)
+	loc := Location{
+		File:   c.relPath(start.Filename),
+		Line:   start.Line,
+		Column: start.Column,
+	}
+	if end.IsValid() {
+		endPosition := c.fset.PositionFor(end, false)
+		loc.EndLine = endPosition.Line
+		loc.EndColumn = endPosition.Column
+	}
+	return loc
+}
+
+func (c *converter) relPath(path string) string {
+	return RelPath(c.baseDir, path)
+}
+
+// RelPath returns slash separated path relative to baseDir if path is inside it.
+func RelPath(baseDir, path string) string {
+	if baseDir != "" {
+		rel, err := filepath.Rel(baseDir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// rpcMethod returns receiver type and name of the method which encloses pos.
+// RPC methods are methods, so it returns empty strings for functions.
+func (c *converter) rpcMethod(pos token.Pos) (string, string) {
+	for _, file := range c.files {
+		if pos < file.FileStart || file.FileEnd < pos {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+		for _, node := range path {
+			decl, ok := node.(*ast.FuncDecl)
+			if !ok || decl.Recv == nil {
+				continue
+			}
+			obj, ok := c.info.Defs[decl.Name].(*types.Func)
+			if !ok {
+				return "", decl.Name.Name
+			}
+			recv := obj.Signature().Recv().Type()
+			if ptr, ok := recv.(*types.Pointer); ok {
+				recv = ptr.Elem()
+			}
+			named, ok := recv.(*types.Named)
+			if !ok {
+				return "", decl.Name.Name
+			}
+			return named.Obj().Pkg().Path() + "." + named.Obj().Name(), decl.Name.Name
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,178 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+
+	"github.com/gostaticanalysis/testutil"
+
+	"github.com/cloverrose/rpcguard/pkg/configtest"
+	"github.com/cloverrose/rpcguard/pkg/report"
+
+	"github.com/cloverrose/rpcguard/passes/wraperr"
+)
+
+// lineOffset is the number of lines testutil.WithModules adds to the source files (//line directive and an empty line).
+const lineOffset = 2
+
+func collect(t *testing.T) *report.Report {
+	t.Helper()
+	testdata := analysistest.TestData()
+	testdata = testutil.WithModules(t, testdata, nil)
+	configtest.Set(t, &wraperr.LogConfig.Level, "ERROR")
+	configtest.Set(t, &wraperr.ReportMode, "RETURN")
+	configtest.Set(t, &wraperr.IncludePackages, "^a$")
+	results := analysistest.Run(t, testdata, wraperr.Analyzer, "a")
+	actions := make([]*checker.Action, len(results))
+	for i, result := range results {
+		actions[i] = result.Action
+	}
+	return report.Collect([]*analysis.Analyzer{wraperr.Analyzer}, actions, filepath.Join(testdata, "src"))
+}
+
+func TestCollect(t *testing.T) {
+	r := collect(t)
+	want := []report.Diagnostic{{
+		RuleID:    "wraperr",
+		Analyzer:  "rpc_wraperr",
+		Severity:  report.SeverityError,
+		Message:   "RPC method Greet returns error that is not wrapped with connect.NewError",
+		Service:   "a.App",
+		Procedure: "Greet",
+		Location:  report.Location{File: "a/a.go", Line: 20 + lineOffset, Column: 3},
+		Related: []report.Related{{
+			Message:  "error may come from a.newErr (bad)",
+			Location: report.Location{File: "a/a.go", Line: 14 + lineOffset, Column: 6},
+		}},
+		SuggestedFixes: []report.SuggestedFix{{
+			Message: "Wrap error with connect.NewError",
+			Edits: []report.Edit{
+				{Location: report.Location{File: "a/a.go", Line: 20 + lineOffset, Column: 15, EndLine: 20 + lineOffset, EndColumn: 15}, NewText: "connect.NewError(connect.CodeInternal, "},
+				{Location: report.Location{File: "a/a.go", Line: 20 + lineOffset, Column: 18, EndLine: 20 + lineOffset, EndColumn: 18}, NewText: ")"},
+			},
+		}},
+	}}
+	if diff := cmp.Diff(want, r.Diagnostics); diff != "" {
+		t.Errorf("Collect() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	r := collect(t)
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID           string            `json:"ruleId"`
+				Level            string            `json:"level"`
+				Properties       map[string]string `json:"properties"`
+				RelatedLocations []json.RawMessage `json:"relatedLocations"`
+				Fixes            []struct {
+					ArtifactChanges []struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Replacements []json.RawMessage `json:"replacements"`
+					} `json:"artifactChanges"`
+				} `json:"fixes"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("unexpected SARIF: %s", buf.String())
+	}
+	run := got.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "wraperr" {
+		t.Errorf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 1 {
+		t.Fatalf("unexpected results: %+v", run.Results)
+	}
+	result := run.Results[0]
+	if diff := cmp.Diff(map[string]string{"service": "a.App", "procedure": "Greet"}, result.Properties); diff != "" {
+		t.Errorf("properties mismatch (-want +got):\n%s", diff)
+	}
+	if result.RuleID != "wraperr" || result.Level != "error" || len(result.RelatedLocations) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Fixes) != 1 || len(result.Fixes[0].ArtifactChanges) != 1 ||
+		result.Fixes[0].ArtifactChanges[0].ArtifactLocation.URI != "a/a.go" ||
+		len(result.Fixes[0].ArtifactChanges[0].Replacements) != 2 {
+		t.Errorf("unexpected fixes: %+v", result.Fixes)
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	t.Parallel()
+	base := filepath.FromSlash("/work/app")
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/work/app/a/a.go", want: "a/a.go"},
+		{path: "/work/app/..gen/x.go", want: "..gen/x.go"},
+		{path: "/work/other/b.go", want: "/work/other/b.go"},
+	}
+	for _, tt := range tests {
+		if got := report.RelPath(base, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("RelPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSARIF_URI(t *testing.T) {
+	t.Parallel()
+	r := &report.Report{Diagnostics: []report.Diagnostic{
+		{RuleID: "wraperr", Location: report.Location{File: "a/a b.go", Line: 1, Column: 1}},
+		{RuleID: "wraperr", Location: report.Location{File: "/home/user/go/pkg/mod/x.go", Line: 1, Column: 1}},
+	}}
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Runs []struct {
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, result := range got.Runs[0].Results {
+		uris = append(uris, result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+	want := []string{"a/a%20b.go", "file:///home/user/go/pkg/mod/x.go"}
+	if diff := cmp.Diff(want, uris); diff != "" {
+		t.Errorf("URI mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,195 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "rpcguard"
+	toolURI      = "https://github.com/cloverrose/rpcguard"
+)
+
+// SARIF 2.1.0 objects. Only properties used by rpcguard are defined.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		Name             string       `json:"name"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID           string                 `json:"ruleId"`
+		Level            string                 `json:"level"`
+		Message          sarifMessage           `json:"message"`
+		Locations        []sarifLocation        `json:"locations"`
+		RelatedLocations []sarifRelatedLocation `json:"relatedLocations,omitempty"`
+		Fixes            []sarifFix             `json:"fixes,omitempty"`
+		Properties       map[string]string      `json:"properties,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifRelatedLocation struct {
+		ID               int                   `json:"id"`
+		Message          sarifMessage          `json:"message"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+	sarifFix struct {
+		Description     sarifMessage          `json:"description"`
+		ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+	}
+	sarifArtifactChange struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Replacements     []sarifReplacement    `json:"replacements"`
+	}
+	sarifReplacement struct {
+		DeletedRegion   sarifRegion  `json:"deletedRegion"`
+		InsertedContent sarifMessage `json:"insertedContent"`
+	}
+)
+
+// WriteJSON writes r as JSON.
+func WriteJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteSARIF writes r as SARIF 2.1.0.
+// Sub rules (e.g. wraperr/unknown) are listed as rules too, since GitHub code scanning requires rule of each result.
+func WriteSARIF(w io.Writer, r *Report) error {
+	driver := sarifDriver{Name: toolName, InformationURI: toolURI, Rules: []sarifRule{}}
+	descriptions := make(map[string]string, len(r.Rules))
+	for _, rule := range r.Rules {
+		descriptions[rule.ID] = rule.Description
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule.ID, Name: rule.Analyzer, ShortDescription: sarifMessage{Text: rule.Description}})
+	}
+	results := make([]sarifResult, 0, len(r.Diagnostics))
+	for _, d := range r.Diagnostics {
+		if _, ok := descriptions[d.RuleID]; !ok {
+			parent, category, _ := strings.Cut(d.RuleID, "/")
+			description := descriptions[parent] + " (" + category + ")"
+			descriptions[d.RuleID] = description
+			driver.Rules = append(driver.Rules, sarifRule{ID: d.RuleID, Name: d.Analyzer, ShortDescription: sarifMessage{Text: description}})
+		}
+		results = append(results, toSARIFResult(d))
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func toSARIFResult(d Diagnostic) sarifResult {
+	result := sarifResult{
+		RuleID:    d.RuleID,
+		Level:     d.Severity,
+		Message:   sarifMessage{Text: d.Message},
+		Locations: []sarifLocation{{PhysicalLocation: toSARIFPhysicalLocation(d.Location)}},
+	}
+	if d.Service != "" || d.Procedure != "" {
+		result.Properties = map[string]string{"service": d.Service, "procedure": d.Procedure}
+	}
+	for i, related := range d.Related {
+		result.RelatedLocations = append(result.RelatedLocations, sarifRelatedLocation{
+			ID:               i,
+			Message:          sarifMessage{Text: related.Message},
+			PhysicalLocation: toSARIFPhysicalLocation(related.Location),
+		})
+	}
+	for _, fix := range d.SuggestedFixes {
+		changes := make(map[string]*sarifArtifactChange)
+		var files []string
+		for _, edit := range fix.Edits {
+			change, ok := changes[edit.Location.File]
+			if !ok {
+				change = &sarifArtifactChange{ArtifactLocation: toSARIFArtifactLocation(edit.Location.File)}
+				changes[edit.Location.File] = change
+				files = append(files, edit.Location.File)
+			}
+			change.Replacements = append(change.Replacements, sarifReplacement{
+				DeletedRegion:   toSARIFRegion(edit.Location),
+				InsertedContent: sarifMessage{Text: edit.NewText},
+			})
+		}
+		sarifFix := sarifFix{Description: sarifMessage{Text: fix.Message}}
+		for _, file := range files {
+			sarifFix.ArtifactChanges = append(sarifFix.ArtifactChanges, *changes[file])
+		}
+		result.Fixes = append(result.Fixes, sarifFix)
+	}
+	return result
+}
+
+func toSARIFPhysicalLocation(loc Location) sarifPhysicalLocation {
+	return sarifPhysicalLocation{
+		ArtifactLocation: toSARIFArtifactLocation(loc.File),
+		Region:           toSARIFRegion(loc),
+	}
+}
+
+// toSARIFArtifactLocation returns the location of file, which is relative to the base directory if possible.
+// Files outside the base directory have absolute paths, they are file URIs. E.g. file:///home/user/go/pkg/mod/x.go
+func toSARIFArtifactLocation(file string) sarifArtifactLocation {
+	uri := &url.URL{Path: file}
+	if filepath.IsAbs(filepath.FromSlash(file)) {
+		uri.Scheme = "file"
+		if !strings.HasPrefix(file, "/") {
+			// Windows drive letter. E.g. C:/x.go -> file:///C:/x.go
+			uri.Path = "/" + file
+		}
+	}
+	return sarifArtifactLocation{URI: uri.String()}
+}
+
+func toSARIFRegion(loc Location) sarifRegion {
+	return sarifRegion{
+		StartLine:   loc.Line,
+		StartColumn: loc.Column,
+		EndLine:     loc.EndLine,
+		EndColumn:   loc.EndColumn,
+	}
+}
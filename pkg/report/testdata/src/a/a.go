@@ -0,0 +1,23 @@
+package a
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+type App struct{}
+
+type Message struct{}
+
+func newErr() error { // want newErr:"badFunc"
+	return errors.New("bad")
+}
+
+func (app *App) Greet(_ context.Context, _ *connect.Request[Message]) (*connect.Response[Message], error) { // want Greet:"badFunc"
+	if err := newErr(); err != nil {
+		return nil, err // want "RPC method Greet returns error that is not wrapped with connect.NewError"
+	}
+	return connect.NewResponse(&Message{}), nil
+}
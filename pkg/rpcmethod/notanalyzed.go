@@ -0,0 +1,23 @@
+package rpcmethod
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// CategoryNotAnalyzed is category of diagnostics for functions that cannot be analyzed.
+const CategoryNotAnalyzed = "unanalyzed"
+
+const notAnalyzedMsg = "rpcguard could not analyze %s: %s"
+
+// ReportNotAnalyzed reports that fn could not be analyzed due to err.
+// Analyzers report it instead of judging fn ok, so that RPC methods are not silently passed.
+func ReportNotAnalyzed(pass *analysis.Pass, fn *ssa.Function, err error) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      fn.Pos(),
+		Category: CategoryNotAnalyzed,
+		Message:  fmt.Sprintf(notAnalyzedMsg, fn.Name(), err),
+	})
+}
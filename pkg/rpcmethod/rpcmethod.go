@@ -3,6 +3,7 @@ package rpcmethod
 import (
 	"errors"
 	"go/types"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/ssa"
@@ -54,10 +55,39 @@ func (c *Checker) IsRPCMethod(fn *ssa.Function) bool {
 	return true
 }
 
+// IsStreamRPCMethod returns true if fn is server streaming or bidi streaming RPC method.
+//
+//	func (s *Server) Foo(ctx context.Context, req *connect.Request[Req], stream *connect.ServerStream[Res]) error
+//	func (s *Server) Foo(ctx context.Context, stream *connect.BidiStream[Req, Res]) error
+func (c *Checker) IsStreamRPCMethod(fn *ssa.Function) bool {
+	sig := fn.Signature
+	if sig.Recv() == nil || sig.Results().Len() != 1 {
+		return false
+	}
+	if !analysisutil.ImplementsError(sig.Results().At(0).Type()) {
+		return false
+	}
+	params := sig.Params()
+	if params.Len() == 0 || params.At(0).Type() != c.rpcTypes.ctxType {
+		return false
+	}
+	switch params.Len() {
+	case 3:
+		return c.loader.checkInnerType(params.At(1).Type(), c.rpcTypes.reqType) &&
+			c.loader.checkInnerType(params.At(2).Type(), c.rpcTypes.serverStreamType)
+	case 2:
+		return c.loader.checkInnerType(params.At(1).Type(), c.rpcTypes.bidiStreamType)
+	default:
+		return false
+	}
+}
+
 type rpcMethodTypes struct {
-	ctxType types.Type
-	reqType types.Type
-	resType types.Type
+	ctxType          types.Type
+	reqType          types.Type
+	resType          types.Type
+	serverStreamType types.Type
+	bidiStreamType   types.Type
 }
 
 type RPCTypesLoader struct {
@@ -77,9 +107,11 @@ func (l *RPCTypesLoader) load() (*rpcMethodTypes, error) {
 		return nil, errors.New("no rpc method")
 	}
 	return &rpcMethodTypes{
-		ctxType: ctxType,
-		reqType: reqType,
-		resType: resType,
+		ctxType:          ctxType,
+		reqType:          reqType,
+		resType:          resType,
+		serverStreamType: l.getInner(l.getServerStreamType()),
+		bidiStreamType:   l.getInner(l.getBidiStreamType()),
 	}, nil
 }
 
@@ -95,6 +127,14 @@ func (l *RPCTypesLoader) getResponseType() types.Type {
 	return analysisutil.TypeOf(l.pass, "connectrpc.com/connect", "*Response")
 }
 
+func (l *RPCTypesLoader) getServerStreamType() types.Type {
+	return analysisutil.TypeOf(l.pass, "connectrpc.com/connect", "*ServerStream")
+}
+
+func (l *RPCTypesLoader) getBidiStreamType() types.Type {
+	return analysisutil.TypeOf(l.pass, "connectrpc.com/connect", "*BidiStream")
+}
+
 // From *connect.Request[FooRequest], get connect.Request[T any]
 func (l *RPCTypesLoader) getInner(tt types.Type) types.Type {
 	ptr, ok := tt.(*types.Pointer)
@@ -112,8 +152,45 @@ func (l *RPCTypesLoader) getInner(tt types.Type) types.Type {
 
 func (l *RPCTypesLoader) checkInnerType(typ, wantType types.Type) bool {
 	ityp := l.getInner(typ)
-	if ityp == nil {
+	if ityp == nil || wantType == nil {
 		return false
 	}
 	return ityp == wantType
 }
+
+// LooksLikeRPCMethod returns true if fn has the signature of unary RPC method, comparing type names instead of types.
+// It is used to find RPC methods in packages where Checker can't be built or isn't used,
+// e.g. connect is vendored or the package is skipped by filters.
+//
+//	func (s *Server) Foo(ctx context.Context, req *connect.Request[Req]) (*connect.Response[Res], error)
+func LooksLikeRPCMethod(fn *ssa.Function) bool {
+	sig := fn.Signature
+	if sig.Recv() == nil || sig.Params().Len() != 2 || sig.Results().Len() != 2 {
+		return false
+	}
+	return isNamed(sig.Params().At(0).Type(), "context", "Context") &&
+		isConnectPointer(sig.Params().At(1).Type(), "Request") &&
+		isConnectPointer(sig.Results().At(0).Type(), "Response") &&
+		analysisutil.ImplementsError(sig.Results().At(1).Type())
+}
+
+const connectPath = "connectrpc.com/connect"
+
+func isConnectPointer(typ types.Type, name string) bool {
+	ptr, ok := typ.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.Obj().Name() != name || named.Obj().Pkg() == nil {
+		return false
+	}
+	// vendored connect has path like example.com/app/vendor/connectrpc.com/connect
+	path := named.Obj().Pkg().Path()
+	return path == connectPath || strings.HasSuffix(path, "/vendor/"+connectPath)
+}
+
+func isNamed(typ types.Type, pkg, name string) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkg && named.Obj().Name() == name
+}
@@ -9,7 +9,11 @@ import (
 )
 
 func ErrIshIndices(fn *ssa.Function) []int {
-	sig := fn.Signature
+	return ErrIshResultIndices(fn.Signature)
+}
+
+// ErrIshResultIndices returns indices of results of sig that are error or higher order function returns error.
+func ErrIshResultIndices(sig *types.Signature) []int {
 	retLen := sig.Results().Len()
 	indices := make([]int, 0, retLen)
 	for i := range retLen {
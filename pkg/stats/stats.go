@@ -0,0 +1,192 @@
+// Package stats records durations of analysis phases and counters of a pass, to find hot spots.
+package stats
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Stats is durations of phases and counters of a pass.
+// All methods are no-op for nil Stats, so that callers don't need to check whether stats are enabled.
+type Stats struct {
+	Analyzer string         `json:"analyzer"`
+	Package  string         `json:"package"`
+	Phases   []Phase        `json:"phases"` // in execution order.
+	Counters map[string]int `json:"counters"`
+}
+
+// Phase is a duration of a phase.
+type Phase struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"durationNs"`
+}
+
+// New returns new Stats.
+func New(analyzer, pkg string) *Stats {
+	return &Stats{Analyzer: analyzer, Package: pkg, Phases: []Phase{}, Counters: make(map[string]int)}
+}
+
+// Start starts phase name, and returns func to end it.
+// Durations of the same name are summed.
+func (s *Stats) Start(name string) (end func()) {
+	if s == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		if i := slices.IndexFunc(s.Phases, func(p Phase) bool { return p.Name == name }); i >= 0 {
+			s.Phases[i].Duration += d
+			return
+		}
+		s.Phases = append(s.Phases, Phase{Name: name, Duration: d})
+	}
+}
+
+// Add adds n to counter name.
+func (s *Stats) Add(name string, n int) {
+	if s == nil {
+		return
+	}
+	s.Counters[name] += n
+}
+
+// Max sets counter name to n if n is larger.
+func (s *Stats) Max(name string, n int) {
+	if s == nil {
+		return
+	}
+	s.Counters[name] = max(s.Counters[name], n)
+}
+
+// WriteDir writes s as JSON to dir. File name is the analyzer name and the package path escaped by url.PathEscape,
+// e.g. rpc_wraperr.github.com%2Ffoo%2Fbar.json.
+func (s *Stats) WriteDir(dir string) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	name := s.Analyzer + "." + url.PathEscape(s.Package) + ".json"
+	return os.WriteFile(filepath.Join(dir, name), data, 0o600)
+}
+
+// ReadDir reads all Stats written by WriteDir, sorted by analyzer and package.
+func ReadDir(dir string) ([]*Stats, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Stats
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s := &Stats{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		ret = append(ret, s)
+	}
+	slices.SortFunc(ret, func(a, b *Stats) int {
+		return cmp.Or(strings.Compare(a.Analyzer, b.Analyzer), strings.Compare(a.Package, b.Package))
+	})
+	return ret, nil
+}
+
+// Summary is Stats of all packages.
+type Summary struct {
+	Analyzers []*AnalyzerSummary `json:"analyzers"`
+	Packages  []*Stats           `json:"packages"`
+}
+
+// AnalyzerSummary sums Stats of an analyzer.
+type AnalyzerSummary struct {
+	Analyzer string `json:"analyzer"`
+	Packages int    `json:"packages"`
+	// Duration is the total execution time of the analyzer, including packages without Stats (e.g. dependencies).
+	Duration time.Duration `json:"durationNs"`
+	Phases   []Phase       `json:"phases"`
+	// Counters are summed, except counters with ".max" suffix which are maximum.
+	Counters map[string]int `json:"counters"`
+	// Slowest are the packages which took the longest in total of phases, up to 10.
+	Slowest []PackageDuration `json:"slowest"`
+}
+
+// PackageDuration is total duration of phases of a package.
+type PackageDuration struct {
+	Package  string        `json:"package"`
+	Duration time.Duration `json:"durationNs"`
+}
+
+const slowestLimit = 10
+
+// Summarize sums packages per analyzer. durations are total execution time of each analyzer.
+func Summarize(packages []*Stats, durations map[string]time.Duration) *Summary {
+	summaries := make(map[string]*AnalyzerSummary)
+	get := func(analyzer string) *AnalyzerSummary {
+		if _, ok := summaries[analyzer]; !ok {
+			summaries[analyzer] = &AnalyzerSummary{Analyzer: analyzer, Phases: []Phase{}, Counters: make(map[string]int), Slowest: []PackageDuration{}}
+		}
+		return summaries[analyzer]
+	}
+	for analyzer, d := range durations {
+		get(analyzer).Duration = d
+	}
+	for _, s := range packages {
+		sum := get(s.Analyzer)
+		sum.Packages++
+		var total time.Duration
+		for _, p := range s.Phases {
+			total += p.Duration
+			if i := slices.IndexFunc(sum.Phases, func(q Phase) bool { return q.Name == p.Name }); i >= 0 {
+				sum.Phases[i].Duration += p.Duration
+			} else {
+				sum.Phases = append(sum.Phases, p)
+			}
+		}
+		for name, n := range s.Counters {
+			if strings.HasSuffix(name, ".max") {
+				sum.Counters[name] = max(sum.Counters[name], n)
+			} else {
+				sum.Counters[name] += n
+			}
+		}
+		sum.Slowest = append(sum.Slowest, PackageDuration{Package: s.Package, Duration: total})
+	}
+	ret := &Summary{Analyzers: []*AnalyzerSummary{}, Packages: packages}
+	if ret.Packages == nil {
+		ret.Packages = []*Stats{}
+	}
+	for _, analyzer := range slices.Sorted(maps.Keys(summaries)) {
+		sum := summaries[analyzer]
+		slices.SortStableFunc(sum.Slowest, func(a, b PackageDuration) int {
+			return cmp.Compare(b.Duration, a.Duration)
+		})
+		sum.Slowest = sum.Slowest[:min(len(sum.Slowest), slowestLimit)]
+		ret.Analyzers = append(ret.Analyzers, sum)
+	}
+	return ret
+}
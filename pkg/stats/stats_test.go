@@ -0,0 +1,109 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/cloverrose/rpcguard/pkg/stats"
+)
+
+func TestStats_Nil(t *testing.T) {
+	var st *stats.Stats
+	// must not panic.
+	st.Start("phase")()
+	st.Add("counter", 1)
+	st.Max("counter.max", 1)
+	if err := st.WriteDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStats_Start(t *testing.T) {
+	st := stats.New("rpc_wraperr", "a")
+	st.Start("callgraph")()
+	st.Start("mark")()
+	st.Start("callgraph")()
+	var names []string
+	for _, p := range st.Phases {
+		names = append(names, p.Name)
+	}
+	if diff := cmp.Diff([]string{"callgraph", "mark"}, names); diff != "" {
+		t.Errorf("phases mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteDirAndSummarize(t *testing.T) {
+	dir := t.TempDir()
+	a := &stats.Stats{
+		Analyzer: "rpc_wraperr",
+		Package:  "example.com/a",
+		Phases:   []stats.Phase{{Name: "callgraph", Duration: 3}, {Name: "mark", Duration: 1}},
+		Counters: map[string]int{"funcs.target": 2, "scc.size.max": 3},
+	}
+	b := &stats.Stats{
+		Analyzer: "rpc_wraperr",
+		Package:  "example.com/b",
+		Phases:   []stats.Phase{{Name: "callgraph", Duration: 10}, {Name: "model:errgroup", Duration: 5}},
+		Counters: map[string]int{"funcs.target": 5, "scc.size.max": 2},
+	}
+	for _, st := range []*stats.Stats{b, a} {
+		if err := st.WriteDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packages, err := stats.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*stats.Stats{a, b}, packages); diff != "" {
+		t.Errorf("ReadDir() mismatch (-want +got):\n%s", diff)
+	}
+
+	got := stats.Summarize(packages, map[string]time.Duration{"rpc_wraperr": 100, "buildssa": 50})
+	want := &stats.Summary{
+		Analyzers: []*stats.AnalyzerSummary{
+			{
+				Analyzer: "buildssa",
+				Duration: 50,
+				Phases:   []stats.Phase{},
+				Counters: map[string]int{},
+				Slowest:  []stats.PackageDuration{},
+			},
+			{
+				Analyzer: "rpc_wraperr",
+				Packages: 2,
+				Duration: 100,
+				Phases:   []stats.Phase{{Name: "callgraph", Duration: 13}, {Name: "mark", Duration: 1}, {Name: "model:errgroup", Duration: 5}},
+				Counters: map[string]int{"funcs.target": 7, "scc.size.max": 3},
+				Slowest:  []stats.PackageDuration{{Package: "example.com/b", Duration: 15}, {Package: "example.com/a", Duration: 4}},
+			},
+		},
+		Packages: []*stats.Stats{a, b},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteDir_PackagePathCollision(t *testing.T) {
+	dir := t.TempDir()
+	// a/b_c and a_b/c would be the same file name if "/" was replaced by "_".
+	for _, pkg := range []string{"a/b_c", "a_b/c"} {
+		if err := stats.New("rpc_wraperr", pkg).WriteDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packages, err := stats.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, st := range packages {
+		got = append(got, st.Package)
+	}
+	if diff := cmp.Diff([]string{"a/b_c", "a_b/c"}, got); diff != "" {
+		t.Errorf("ReadDir() mismatch (-want +got):\n%s", diff)
+	}
+}